@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -13,40 +14,177 @@ type Node struct {
 }
 
 // parseExpression converts a propositional logic string into a syntax tree.
-func parseExpression(expr string) *Node {
-	expr = strings.TrimSpace(expr)
-	// Handling parentheses
-	if expr[0] == '(' && expr[len(expr)-1] == ')' {
-		return parseExpression(expr[1 : len(expr)-1])
-	}
-	// Splitting logical operators
-	operators := []string{"->", "<->", "|", "&", "!"}
+// A missing operand or operator is reported as a ParseError at its byte offset.
+func parseExpression(expr string) (*Node, error) {
+	return parseSubexpression(expr, 0, "")
+}
+
+// parseSubexpression parses expr, found at byte offset pos of the input, as the operand of
+// the given operator or parenthesis, which an empty operand is reported against
+func parseSubexpression(expr string, pos int, operator string) (*Node, error) {
+	trimmed := strings.TrimLeft(expr, " \t\r\n")
+	pos += len(expr) - len(trimmed)
+	expr = strings.TrimSpace(trimmed)
+	if expr == "" {
+		return nil, &ParseError{Pos: pos, Token: operator, Msg: "missing operand"}
+	}
+	// Handling parentheses around the whole expression
+	if len(expr) > 1 && expr[0] == '(' && closingParen(expr) == len(expr)-1 {
+		return parseSubexpression(expr[1:len(expr)-1], pos+1, "(")
+	}
+	// Splitting logical operators outside parentheses
+	operators := []string{"->", "<->", "|", "&"}
 	for _, op := range operators {
-		pos := strings.LastIndex(expr, op)
-		if pos != -1 {
-			left := parseExpression(expr[:pos])
-			right := parseExpression(expr[pos+len(op):])
-			return &Node{Value: op, Left: left, Right: right}
+		i := lastOperator(expr, op)
+		if i != -1 {
+			left, err := parseSubexpression(expr[:i], pos, op)
+			if err != nil {
+				return nil, err
+			}
+			right, err := parseSubexpression(expr[i+len(op):], pos+i+len(op), op)
+			if err != nil {
+				return nil, err
+			}
+			return &Node{Value: op, Left: left, Right: right}, nil
+		}
+	}
+	// Negation applies to the rest of the expression
+	if strings.HasPrefix(expr, "!") {
+		operand, err := parseSubexpression(expr[1:], pos+1, "!")
+		if err != nil {
+			return nil, err
+		}
+		return &Node{Value: "!", Left: operand}, nil
+	}
+	// Leaf node; 1 and 0 are the constants TRUE and FALSE
+	if strings.ContainsAny(expr, "() \t\r\n") {
+		return nil, &ParseError{Pos: pos, Token: expr, Msg: "missing operator"}
+	}
+	switch expr {
+	case "1":
+		expr = "TRUE"
+	case "0":
+		expr = "FALSE"
+	}
+	return &Node{Value: expr}, nil
+}
+
+// closingParen returns the index of the parenthesis closing the one that opens expr, or -1
+func closingParen(expr string) int {
+	depth := 0
+	for i := 0; i < len(expr); i++ {
+		if expr[i] == '(' {
+			depth++
+		} else if expr[i] == ')' {
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// lastOperator returns the index of the last occurrence of op outside parentheses, or -1.
+// The "->" inside "<->" does not count as an implication.
+func lastOperator(expr, op string) int {
+	depth := 0
+	for i := len(expr) - 1; i >= 0; i-- {
+		switch expr[i] {
+		case ')':
+			depth++
+		case '(':
+			depth--
+		}
+		if depth == 0 && strings.HasPrefix(expr[i:], op) && (op != "->" || i == 0 || expr[i-1] != '<') {
+			return i
 		}
 	}
-	// Leaf node
-	return &Node{Value: expr}
+	return -1
 }
 
-// eliminateImplications removes implications and equivalences.
-func eliminateImplications(node *Node) *Node {
+// SimplifyConstants folds the constants TRUE and FALSE into the tree: a negated constant becomes
+// the other constant, and an operator with a constant operand is replaced by its value or by the
+// other operand, negated when needed. The result has no constants unless it is one itself.
+// It modifies the tree in place.
+func SimplifyConstants(node *Node) *Node {
 	if node == nil {
 		return nil
 	}
-	node.Left = eliminateImplications(node.Left)
-	node.Right = eliminateImplications(node.Right)
+	node.Left = SimplifyConstants(node.Left)
+	node.Right = SimplifyConstants(node.Right)
+	isTrue := func(n *Node) bool { return n.Value == "TRUE" }
+	isFalse := func(n *Node) bool { return n.Value == "FALSE" }
+	constant := func(value bool) *Node {
+		if value {
+			return &Node{Value: "TRUE"}
+		}
+		return &Node{Value: "FALSE"}
+	}
+	not := func(n *Node) *Node { return SimplifyConstants(&Node{Value: "!", Left: n}) }
+	left, right := node.Left, node.Right
+	switch node.Value {
+	case "!":
+		if isTrue(left) || isFalse(left) {
+			return constant(isFalse(left))
+		}
+	case "&":
+		switch {
+		case isFalse(left) || isFalse(right):
+			return constant(false)
+		case isTrue(left):
+			return right
+		case isTrue(right):
+			return left
+		}
+	case "|":
+		switch {
+		case isTrue(left) || isTrue(right):
+			return constant(true)
+		case isFalse(left):
+			return right
+		case isFalse(right):
+			return left
+		}
+	case "->":
+		switch {
+		case isFalse(left) || isTrue(right):
+			return constant(true)
+		case isTrue(left):
+			return right
+		case isFalse(right):
+			return not(left)
+		}
+	case "<->":
+		switch {
+		case isTrue(left):
+			return right
+		case isTrue(right):
+			return left
+		case isFalse(left):
+			return not(right)
+		case isFalse(right):
+			return not(left)
+		}
+	}
+	return node
+}
+
+// EliminateImplications removes implications and equivalences, rewriting them with !, | and &.
+// It modifies the tree in place and is idempotent.
+func EliminateImplications(node *Node) *Node {
+	if node == nil {
+		return nil
+	}
+	node.Left = EliminateImplications(node.Left)
+	node.Right = EliminateImplications(node.Right)
 	switch node.Value {
 	case "->": // A -> B ≡ !A | B
 		node.Value = "|"
-		node.Left = &Node{Value: "!", Left: eliminateImplications(node.Left)}
+		node.Left = &Node{Value: "!", Left: EliminateImplications(node.Left)}
 	case "<->": // A <-> B ≡ (!A | B) & (!B | A)
-		a := eliminateImplications(node.Left)
-		b := eliminateImplications(node.Right)
+		a := EliminateImplications(node.Left)
+		b := EliminateImplications(node.Right)
 		node.Value = "&"
 		node.Left = &Node{Value: "|", Left: &Node{Value: "!", Left: a}, Right: b}
 		node.Right = &Node{Value: "|", Left: &Node{Value: "!", Left: b}, Right: a}
@@ -54,91 +192,499 @@ func eliminateImplications(node *Node) *Node {
 	return node
 }
 
-// pushNegations applies De Morgan's laws to push negations inwards.
-func pushNegations(node *Node) *Node {
+// PushNegations applies De Morgan's laws to push negations inwards. On an implication-free
+// tree it yields negation normal form, where ! only applies to variables. A negated implication
+// or equivalence that is still present is rewritten too. It is idempotent.
+func PushNegations(node *Node) *Node {
 	if node == nil {
 		return nil
 	}
-	switch node.Value {
-	case "!":
-		if node.Left != nil && (node.Left.Value == "&" || node.Left.Value == "|") {
-			// Apply De Morgan's Laws
-			op := "|"
-			if node.Left.Value == "&" {
-				op = "&"
-			}
-			node = &Node{
-				Value: op,
-				Left:  pushNegations(&Node{Value: "!", Left: node.Left.Left}),
-				Right: pushNegations(&Node{Value: "!", Left: node.Left.Right}),
-			}
-		} else if node.Left != nil && node.Left.Value == "!" {
-			// Double negation elimination
-			node = pushNegations(node.Left.Left)
+	if node.Value != "!" || node.Left == nil {
+		node.Left = PushNegations(node.Left)
+		node.Right = PushNegations(node.Right)
+		return node
+	}
+	child := node.Left
+	not := func(n *Node) *Node { return &Node{Value: "!", Left: n} }
+	switch child.Value {
+	case "&": // !(A & B) ≡ !A | !B
+		return &Node{Value: "|", Left: PushNegations(not(child.Left)), Right: PushNegations(not(child.Right))}
+	case "|": // !(A | B) ≡ !A & !B
+		return &Node{Value: "&", Left: PushNegations(not(child.Left)), Right: PushNegations(not(child.Right))}
+	case "->": // !(A -> B) ≡ A & !B
+		return &Node{Value: "&", Left: PushNegations(child.Left), Right: PushNegations(not(child.Right))}
+	case "<->": // !(A <-> B) ≡ (A | B) & (!A | !B)
+		return &Node{
+			Value: "&",
+			Left:  &Node{Value: "|", Left: PushNegations(child.Left), Right: PushNegations(child.Right)},
+			Right: &Node{Value: "|", Left: PushNegations(not(child.Left)), Right: PushNegations(not(child.Right))},
 		}
-	default:
-		node.Left = pushNegations(node.Left)
-		node.Right = pushNegations(node.Right)
+	case "!": // Double negation elimination
+		return PushNegations(child.Left)
+	case "TRUE":
+		return &Node{Value: "FALSE"}
+	case "FALSE":
+		return &Node{Value: "TRUE"}
 	}
 	return node
 }
 
-// distributeOr distributes OR over AND to achieve CNF.
-func distributeOr(node *Node) *Node {
+// DistributeOr distributes OR over AND to achieve CNF. It expects a tree in negation normal
+// form and is idempotent. The result can be exponentially larger than its input.
+func DistributeOr(node *Node) *Node {
 	if node == nil {
 		return nil
 	}
-	node.Left = distributeOr(node.Left)
-	node.Right = distributeOr(node.Right)
+	node.Left = DistributeOr(node.Left)
+	node.Right = DistributeOr(node.Right)
 	if node.Value == "|" {
 		if node.Left != nil && node.Left.Value == "&" {
 			return &Node{
 				Value: "&",
-				Left:  distributeOr(&Node{Value: "|", Left: node.Left.Left, Right: node.Right}),
-				Right: distributeOr(&Node{Value: "|", Left: node.Left.Right, Right: node.Right}),
+				Left:  DistributeOr(&Node{Value: "|", Left: node.Left.Left, Right: node.Right}),
+				Right: DistributeOr(&Node{Value: "|", Left: node.Left.Right, Right: node.Right}),
 			}
 		}
 		if node.Right != nil && node.Right.Value == "&" {
 			return &Node{
 				Value: "&",
-				Left:  distributeOr(&Node{Value: "|", Left: node.Left, Right: node.Right.Left}),
-				Right: distributeOr(&Node{Value: "|", Left: node.Left, Right: node.Right.Right}),
+				Left:  DistributeOr(&Node{Value: "|", Left: node.Left, Right: node.Right.Left}),
+				Right: DistributeOr(&Node{Value: "|", Left: node.Left, Right: node.Right.Right}),
 			}
 		}
 	}
 	return node
 }
 
-// toCNF converts a syntax tree to CNF.
+// toCNF converts a syntax tree to CNF by folding its constants and running the three passes in order.
 func toCNF(node *Node) *Node {
-	node = eliminateImplications(node)
-	node = pushNegations(node)
-	node = distributeOr(node)
+	node = SimplifyConstants(node)
+	node = EliminateImplications(node)
+	node = PushNegations(node)
+	node = DistributeOr(node)
 	return node
 }
 
+// VarAllocator hands out variable numbers for conversions that must share one numbering,
+// so the CNFs of several formulas, with their auxiliary variables, can be conjoined safely.
+// Named variables get the same number in every conversion that uses the allocator.
+type VarAllocator struct {
+	vars map[string]int
+	next int
+}
+
+// NewVarAllocator returns an allocator whose first variable is 1
+func NewVarAllocator() *VarAllocator {
+	return &VarAllocator{vars: make(map[string]int)}
+}
+
+// Fresh returns a variable not used before
+func (a *VarAllocator) Fresh() int {
+	a.next++
+	return a.next
+}
+
+// Var returns the variable of the named leaf, allocating it on first use
+func (a *VarAllocator) Var(name string) int {
+	if _, exists := a.vars[name]; !exists {
+		a.vars[name] = a.Fresh()
+	}
+	return a.vars[name]
+}
+
+// Vars returns a copy of the numbering of the named variables allocated so far
+func (a *VarAllocator) Vars() map[string]int {
+	vars := make(map[string]int, len(a.vars))
+	for name, variable := range a.vars {
+		vars[name] = variable
+	}
+	return vars
+}
+
+// NumVariables returns the number of variables allocated so far
+func (a *VarAllocator) NumVariables() int {
+	return a.next
+}
+
+// NodeToCNF converts a syntax tree to CNF by distribution and numbers its variables from 1.
+// The constants TRUE and FALSE are honoured: a clause left without literals becomes the
+// empty Clause{}, which is false, and a clause containing TRUE is dropped.
+func NodeToCNF(node *Node) (CNF, map[string]int) {
+	alloc := NewVarAllocator()
+	return NodeToCNFWith(node, alloc), alloc.Vars()
+}
+
+// NodeToCNFWith is NodeToCNF numbering the variables through alloc
+func NodeToCNFWith(node *Node, alloc *VarAllocator) CNF {
+	return extractClauses(toCNF(node), alloc)
+}
+
+// extractClauses collects the clauses of a tree in CNF, numbering new variables through alloc
+func extractClauses(node *Node, alloc *VarAllocator) CNF {
+	if node.Value == "&" {
+		return append(extractClauses(node.Left, alloc), extractClauses(node.Right, alloc)...)
+	}
+	clause, satisfied := extractLiterals(node, alloc)
+	if satisfied {
+		return CNF{}
+	}
+	return CNF{clause}
+}
+
+// extractLiterals collects the literals of a disjunction, or reports that it contains TRUE
+func extractLiterals(node *Node, alloc *VarAllocator) (Clause, bool) {
+	switch node.Value {
+	case "|":
+		left, satisfied := extractLiterals(node.Left, alloc)
+		right, satisfiedRight := extractLiterals(node.Right, alloc)
+		return append(left, right...), satisfied || satisfiedRight
+	case "!":
+		literal, satisfied := extractLiterals(node.Left, alloc)
+		if len(literal) == 0 { // Negated constant
+			return Clause{}, !satisfied
+		}
+		return Clause{-literal[0]}, false
+	case "TRUE":
+		return Clause{}, true
+	case "FALSE":
+		return Clause{}, false
+	}
+	return Clause{alloc.Var(node.Value)}, false
+}
+
 // printExpression converts a syntax tree back to a string representation.
 func printExpression(node *Node) string {
+	var b strings.Builder
+	writeExpression(&b, node)
+	return b.String()
+}
+
+// writeExpression writes the expression of the tree to b in a single pass
+func writeExpression(b *strings.Builder, node *Node) {
 	if node == nil {
-		return ""
+		return
 	}
 	if node.Left == nil && node.Right == nil {
-		return node.Value
+		b.WriteString(node.Value)
+		return
 	}
 	if node.Right == nil {
-		return fmt.Sprintf("!(%s)", printExpression(node.Left))
+		b.WriteString("!(")
+		writeExpression(b, node.Left)
+		b.WriteByte(')')
+		return
+	}
+	b.WriteByte('(')
+	writeExpression(b, node.Left)
+	b.WriteByte(' ')
+	b.WriteString(node.Value)
+	b.WriteByte(' ')
+	writeExpression(b, node.Right)
+	b.WriteByte(')')
+}
+
+// tseitin encodes the tree into clauses that define a variable for every operator.
+// Leaves are numbered through vars, new variables are taken from next,
+// and the literal standing for the whole tree is returned. The constants TRUE and FALSE
+// become LiteralTrue and LiteralFalse rather than variables.
+func tseitin(node *Node, vars map[string]int, next *int) (CNF, int) {
+	if node.Left == nil && node.Right == nil {
+		switch node.Value {
+		case "TRUE":
+			return CNF{}, LiteralTrue
+		case "FALSE":
+			return CNF{}, LiteralFalse
+		}
+		if _, exists := vars[node.Value]; !exists {
+			*next++
+			vars[node.Value] = *next
+		}
+		return CNF{}, vars[node.Value]
+	}
+	if node.Value == "!" {
+		cnf, a := tseitin(node.Left, vars, next)
+		return cnf, -a
+	}
+	cnf, a := tseitin(node.Left, vars, next)
+	right, b := tseitin(node.Right, vars, next)
+	cnf = append(cnf, right...)
+	*next++
+	g := *next
+	return append(cnf, gateClauses(node.Value, g, a, b)...), g
+}
+
+// gateClauses defines g as the binary operator applied to the literals a and b
+func gateClauses(op string, g, a, b int) CNF {
+	switch op {
+	case "&": // g <-> a & b
+		return CNF{Clause{-g, a}, Clause{-g, b}, Clause{g, -a, -b}}
+	case "|": // g <-> a | b
+		return CNF{Clause{-g, a, b}, Clause{g, -a}, Clause{g, -b}}
+	case "->": // g <-> !a | b
+		return CNF{Clause{-g, -a, b}, Clause{g, a}, Clause{g, -b}}
+	case "<->": // g <-> (a <-> b)
+		return CNF{Clause{-g, -a, b}, Clause{-g, a, -b}, Clause{g, a, b}, Clause{g, -a, -b}}
+	}
+	return CNF{}
+}
+
+// toCNFTseitin converts a syntax tree to an equisatisfiable CNF using the Tseitin encoding.
+// It returns the clauses and the number of variables used.
+func toCNFTseitin(node *Node) (CNF, int) {
+	alloc := NewVarAllocator()
+	return TseitinWith(node, alloc), alloc.NumVariables()
+}
+
+// TseitinWith converts a syntax tree to an equisatisfiable CNF using the Tseitin encoding,
+// taking its variables from alloc so that it can be conjoined with other conversions
+func TseitinWith(node *Node, alloc *VarAllocator) CNF {
+	cnf, root := tseitin(node, alloc.vars, &alloc.next)
+	return append(cnf, Clause{root})
+}
+
+// plaistedGreenbaum is tseitin restricted to the implications needed for the polarities
+// in which the subformula occurs: pos when it must imply its variable's truth, neg for falsity.
+func plaistedGreenbaum(node *Node, vars map[string]int, next *int, pos, neg bool) (CNF, int) {
+	if node.Left == nil && node.Right == nil {
+		return tseitin(node, vars, next)
+	}
+	if node.Value == "!" {
+		cnf, a := plaistedGreenbaum(node.Left, vars, next, neg, pos)
+		return cnf, -a
+	}
+	leftPos, leftNeg := pos, neg
+	rightPos, rightNeg := pos, neg
+	switch node.Value {
+	case "->": // The antecedent occurs negated
+		leftPos, leftNeg = neg, pos
+	case "<->": // Both sides occur in both polarities
+		leftPos, leftNeg, rightPos, rightNeg = true, true, true, true
+	}
+	cnf, a := plaistedGreenbaum(node.Left, vars, next, leftPos, leftNeg)
+	right, b := plaistedGreenbaum(node.Right, vars, next, rightPos, rightNeg)
+	cnf = append(cnf, right...)
+	*next++
+	g := *next
+	switch node.Value {
+	case "&":
+		if pos {
+			cnf = append(cnf, Clause{-g, a}, Clause{-g, b})
+		}
+		if neg {
+			cnf = append(cnf, Clause{g, -a, -b})
+		}
+	case "|":
+		if pos {
+			cnf = append(cnf, Clause{-g, a, b})
+		}
+		if neg {
+			cnf = append(cnf, Clause{g, -a}, Clause{g, -b})
+		}
+	case "->":
+		if pos {
+			cnf = append(cnf, Clause{-g, -a, b})
+		}
+		if neg {
+			cnf = append(cnf, Clause{g, a}, Clause{g, -b})
+		}
+	case "<->":
+		if pos {
+			cnf = append(cnf, Clause{-g, -a, b}, Clause{-g, a, -b})
+		}
+		if neg {
+			cnf = append(cnf, Clause{g, a, b}, Clause{g, -a, -b})
+		}
 	}
-	return fmt.Sprintf("(%s %s %s)", printExpression(node.Left), node.Value, printExpression(node.Right))
+	return cnf, g
+}
+
+// toCNFPlaistedGreenbaum converts a syntax tree to an equisatisfiable CNF using the
+// polarity-aware Plaisted-Greenbaum encoding, which emits fewer clauses than toCNFTseitin.
+// It returns the clauses and the number of variables used.
+func toCNFPlaistedGreenbaum(node *Node) (CNF, int) {
+	alloc := NewVarAllocator()
+	return PlaistedGreenbaumWith(node, alloc), alloc.NumVariables()
 }
 
-// Main function
-func main() {
+// PlaistedGreenbaumWith is toCNFPlaistedGreenbaum taking its variables from alloc
+func PlaistedGreenbaumWith(node *Node, alloc *VarAllocator) CNF {
+	cnf, root := plaistedGreenbaum(node, alloc.vars, &alloc.next, true, false)
+	return append(cnf, Clause{root})
+}
+
+// Eval evaluates the formula tree under the assignment of its variables. The leaves TRUE and FALSE
+// are constants; any other leaf missing from the assignment is an error.
+func Eval(node *Node, assignment map[string]bool) (bool, error) {
+	switch node.Value {
+	case "!":
+		value, err := Eval(node.Left, assignment)
+		return !value, err
+	case "&", "|", "->", "<->":
+		left, err := Eval(node.Left, assignment)
+		if err != nil {
+			return false, err
+		}
+		right, err := Eval(node.Right, assignment)
+		if err != nil {
+			return false, err
+		}
+		switch node.Value {
+		case "&":
+			return left && right, nil
+		case "|":
+			return left || right, nil
+		case "->":
+			return !left || right, nil
+		}
+		return left == right, nil
+	case "TRUE":
+		return true, nil
+	case "FALSE":
+		return false, nil
+	}
+	value, exists := assignment[node.Value]
+	if !exists {
+		return false, fmt.Errorf("eval: variable %q is not assigned", node.Value)
+	}
+	return value, nil
+}
+
+// truthTableMaxVariables bounds the size of the tables printed by TruthTable
+const truthTableMaxVariables = 12
+
+// TruthTable returns the truth table of the formula: one column per variable, in order of name,
+// and a last column with the value of the formula, with one row per assignment written with T and F.
+// It refuses formulas with more than truthTableMaxVariables variables.
+func TruthTable(expr string) (string, error) {
+	if strings.TrimSpace(expr) == "" {
+		return "", ErrEmptyInput
+	}
+	if err := CheckParentheses(expr); err != nil {
+		return "", err
+	}
+	root, err := parseExpression(expr)
+	if err != nil {
+		return "", err
+	}
+	names := variableNames(root)
+	if len(names) > truthTableMaxVariables {
+		return "", fmt.Errorf("truth table: %d variables, at most %d are supported", len(names), truthTableMaxVariables)
+	}
+
+	header := append(append([]string{}, names...), strings.TrimSpace(expr))
+	var b strings.Builder
+	writeRow := func(cells []string) {
+		for i, cell := range cells {
+			if i > 0 {
+				b.WriteString(" | ")
+			}
+			b.WriteString(cell)
+			if i < len(cells)-1 {
+				b.WriteString(strings.Repeat(" ", max(len(header[i]), 1)-len(cell)))
+			}
+		}
+		b.WriteString("\n")
+	}
+	writeRow(header)
+	rule := make([]string, len(header))
+	for i, cell := range header {
+		rule[i] = strings.Repeat("-", max(len(cell), 1))
+	}
+	b.WriteString(strings.Join(rule, "-+-") + "\n")
+	cell := map[bool]string{true: "T", false: "F"}
+	for row := 0; row < 1<<uint(len(names)); row++ {
+		values := make(map[string]bool)
+		cells := []string{}
+		for i, name := range names { // The first variable changes slowest
+			values[name] = row>>uint(len(names)-1-i)&1 == 1
+			cells = append(cells, cell[values[name]])
+		}
+		holds, _ := Eval(root, values)
+		writeRow(append(cells, cell[holds]))
+	}
+	return b.String(), nil
+}
+
+// variableNames returns the sorted names of the variables of the tree, leaving out TRUE and FALSE
+func variableNames(node *Node) []string {
+	seen := make(map[string]bool)
+	var walk func(node *Node)
+	walk = func(node *Node) {
+		if node == nil {
+			return
+		}
+		if node.Left == nil && node.Right == nil && node.Value != "TRUE" && node.Value != "FALSE" {
+			seen[node.Value] = true
+		}
+		walk(node.Left)
+		walk(node.Right)
+	}
+	walk(node)
+	names := []string{}
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Entails reports whether every assignment satisfying premise also satisfies conclusion,
+// by checking that premise & !conclusion is unsatisfiable. When it is not, the satisfying
+// assignment of that formula is returned as a counterexample over the variable names.
+func Entails(premise, conclusion string) (bool, map[string]bool, error) {
+	for _, expr := range []string{premise, conclusion} {
+		if strings.TrimSpace(expr) == "" {
+			return false, nil, ErrEmptyInput
+		}
+		if err := CheckParentheses(expr); err != nil {
+			return false, nil, err
+		}
+	}
+	left, err := parseExpression(premise)
+	if err != nil {
+		return false, nil, err
+	}
+	right, err := parseExpression(conclusion)
+	if err != nil {
+		return false, nil, err
+	}
+	node := &Node{Value: "&", Left: left, Right: &Node{Value: "!", Left: right}}
+	vars := make(map[string]int)
+	next := 0
+	cnf, root := tseitin(node, vars, &next)
+	cnf = append(cnf, Clause{root})
+
+	assignment := make(map[int]bool)
+	if !DPLL(cnf, assignment) {
+		return true, nil, nil
+	}
+	assignment = CompleteAssignment(cnf, assignment)
+	counterexample := make(map[string]bool)
+	for name, variable := range vars {
+		counterexample[name] = assignment[variable]
+	}
+	return false, counterexample, nil
+}
+
+// convertExample prints the CNF of an expression, or of a built-in example when it is empty
+func convertExample(expression string) {
 	// Example input
-	expression := "(A -> B) & (C | D) & (E -> F) & (G | H) | (I -> J) & (K | L)"
+	if expression == "" {
+		expression = "(A -> B) & (C | D) & (E -> F) & (G | H) | (I -> J) & (K | L)"
+	}
 	fmt.Println("Original Expression:", expression)
-
-	// Parse the expression into a syntax tree
-	root := parseExpression(expression)
+	err := CheckParentheses(expression)
+	var root *Node
+	if err == nil {
+		// Parse the expression into a syntax tree
+		root, err = parseExpression(expression)
+	}
+	if err != nil {
+		fmt.Println("Invalid expression:", err)
+		fmt.Println("  " + expression)
+		fmt.Println("  " + strings.Repeat(" ", err.(*ParseError).Pos) + "^")
+		return
+	}
 
 	// Convert to CNF
 	cnfRoot := toCNF(root)
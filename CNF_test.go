@@ -0,0 +1,357 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTruthTable(t *testing.T) {
+	table, err := TruthTable("A & B")
+	want := "A | B | A & B\n" +
+		"--+---+------\n" +
+		"F | F | F\n" +
+		"F | T | F\n" +
+		"T | F | F\n" +
+		"T | T | T\n"
+	if err != nil || table != want {
+		t.Fatalf("got %q, %v, want %q", table, err, want)
+	}
+
+	names := []string{}
+	for i := 0; i <= truthTableMaxVariables; i++ {
+		names = append(names, fmt.Sprintf("X%d", i))
+	}
+	if _, err := TruthTable(strings.Join(names, " | ")); err == nil {
+		t.Fatalf("a formula with %d variables was tabulated", len(names))
+	}
+	if table, err := TruthTable(strings.Join(names[1:], " | ")); err != nil || strings.Count(table, "\n") != 2+1<<truthTableMaxVariables {
+		t.Fatalf("a formula with %d variables: got %d lines, %v", truthTableMaxVariables, strings.Count(table, "\n"), err)
+	}
+}
+
+func TestTruthTableMalformed(t *testing.T) {
+	tests := []struct {
+		expr string
+		pos  int
+	}{
+		{"A &", 3},
+		{"& A", 0},
+		{"A ->", 4},
+		{"!", 1},
+		{"()", 1},
+		{"(A) B", 0},
+	}
+	for _, test := range tests {
+		_, err := TruthTable(test.expr)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) || parseErr.Pos != test.pos {
+			t.Errorf("TruthTable(%q): got %v, want a ParseError at %d", test.expr, err, test.pos)
+		}
+	}
+}
+
+func TestEntails(t *testing.T) {
+	if holds, counterexample, err := Entails("A & (A -> B)", "B"); !holds || counterexample != nil || err != nil {
+		t.Fatal(holds, counterexample, err)
+	}
+	holds, counterexample, err := Entails("A | B", "A")
+	if holds || err != nil || counterexample["A"] || !counterexample["B"] {
+		t.Fatal(holds, counterexample, err)
+	}
+	if holds, _, err := Entails("A & !A", "C"); !holds || err != nil {
+		t.Fatal("a contradictory premise entails everything", err)
+	}
+}
+
+func TestEntailsMalformed(t *testing.T) {
+	for _, pair := range [][2]string{{"A &", "A"}, {"!", "A"}, {"A", "B |"}} {
+		holds, counterexample, err := Entails(pair[0], pair[1])
+		var parseErr *ParseError
+		if holds || counterexample != nil || !errors.As(err, &parseErr) {
+			t.Errorf("Entails(%q, %q): got %v, %v, %v, want a ParseError", pair[0], pair[1], holds, counterexample, err)
+		}
+	}
+}
+
+func mustParse(t *testing.T, expr string) *Node {
+	t.Helper()
+	node, err := parseExpression(expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return node
+}
+
+func TestPlaistedGreenbaum(t *testing.T) {
+	for _, expr := range []string{
+		"(A -> B) & (C | D)",
+		"(A -> B) & A & (C | D) & (B -> !C)",
+		"!(A & B) | (C & !(D | E))",
+	} {
+		tseitin, _ := toCNFTseitin(mustParse(t, expr))
+		pg, _ := toCNFPlaistedGreenbaum(mustParse(t, expr))
+		if len(pg) >= len(tseitin) {
+			t.Errorf("%s: %d clauses, full Tseitin has %d", expr, len(pg), len(tseitin))
+		}
+		if DPLL(pg, map[int]bool{}) != DPLL(tseitin, map[int]bool{}) {
+			t.Errorf("%s: the encodings disagree on satisfiability", expr)
+		}
+	}
+	pg, _ := toCNFPlaistedGreenbaum(mustParse(t, "(A -> B) & A & !B"))
+	if DPLL(pg, map[int]bool{}) {
+		t.Error("an unsatisfiable formula got a satisfiable encoding")
+	}
+}
+
+func TestNodeToCNFContradiction(t *testing.T) {
+	cnf, vars := NodeToCNF(mustParse(t, "A & !A"))
+	if want := (CNF{{1}, {-1}}); !reflect.DeepEqual(cnf, want) || len(vars) != 1 {
+		t.Fatalf("got %v, %v, want %v", cnf, vars, want)
+	}
+	if DPLL(cnf, map[int]bool{}) {
+		t.Fatal("A & !A was reported satisfiable")
+	}
+	cnf, _ = NodeToCNF(mustParse(t, "FALSE | FALSE"))
+	if want := (CNF{{}}); !reflect.DeepEqual(cnf, want) || DPLL(cnf, map[int]bool{}) {
+		t.Fatalf("an empty disjunction: got %v, want %v and UNSAT", cnf, want)
+	}
+	cnf, _ = NodeToCNF(mustParse(t, "(A | TRUE) & (B | !TRUE)"))
+	if len(cnf) != 1 || len(cnf[0]) != 1 {
+		t.Fatalf("got %v, want only the clause for B", cnf)
+	}
+}
+
+func TestConversionPasses(t *testing.T) {
+	tests := []struct {
+		pass      func(*Node) *Node
+		expr      string
+		forbidden func(*Node) bool // Reports a node the pass should have removed
+	}{
+		{EliminateImplications, "A -> (B <-> C)", func(n *Node) bool { return n.Value == "->" || n.Value == "<->" }},
+		{PushNegations, "!(A & !(B | C))", func(n *Node) bool { return n.Value == "!" && n.Left.Left != nil }},
+		{DistributeOr, "A | (B & C)", func(n *Node) bool {
+			return n.Value == "|" && (n.Left.Value == "&" || n.Right.Value == "&")
+		}},
+	}
+	for _, test := range tests {
+		original := mustParse(t, test.expr)
+		converted := test.pass(mustParse(t, test.expr))
+		var walk func(n *Node) bool
+		walk = func(n *Node) bool {
+			return n != nil && (test.forbidden(n) || walk(n.Left) || walk(n.Right))
+		}
+		if walk(converted) {
+			t.Errorf("%q: got %s, not in the expected partial normal form", test.expr, printExpression(converted))
+		}
+		names := variableNames(original)
+		for m := 0; m < 1<<len(names); m++ {
+			values := make(map[string]bool)
+			for i, name := range names {
+				values[name] = m&(1<<i) != 0
+			}
+			want, _ := Eval(original, values)
+			if got, _ := Eval(converted, values); got != want {
+				t.Errorf("%q: %s differs under %v", test.expr, printExpression(converted), values)
+			}
+		}
+		again := printExpression(test.pass(mustParse(t, printExpression(converted))))
+		if again != printExpression(converted) {
+			t.Errorf("%q: a second pass changed %s to %s", test.expr, printExpression(converted), again)
+		}
+	}
+	if got := printExpression(EliminateImplications(mustParse(t, "!(A -> B)"))); strings.Contains(got, "->") {
+		t.Errorf("got %s, want the implication rewritten", got)
+	}
+}
+
+// largeTree returns a conjunction of n clauses (x_i | !y_i), nested to the left
+func largeTree(n int) *Node {
+	var tree *Node
+	for i := 0; i < n; i++ {
+		clause := &Node{Value: "|",
+			Left:  &Node{Value: "x" + strconv.Itoa(i)},
+			Right: &Node{Value: "!", Left: &Node{Value: "y" + strconv.Itoa(i)}}}
+		if tree == nil {
+			tree = clause
+		} else {
+			tree = &Node{Value: "&", Left: tree, Right: clause}
+		}
+	}
+	return tree
+}
+
+// sprintfExpression renders the tree by formatting the strings of its subtrees, as
+// printExpression did before it wrote into a single builder
+func sprintfExpression(node *Node) string {
+	if node == nil {
+		return ""
+	}
+	if node.Left == nil && node.Right == nil {
+		return node.Value
+	}
+	if node.Right == nil {
+		return fmt.Sprintf("!(%s)", sprintfExpression(node.Left))
+	}
+	return fmt.Sprintf("(%s %s %s)", sprintfExpression(node.Left), node.Value, sprintfExpression(node.Right))
+}
+
+func TestPrintExpression(t *testing.T) {
+	tree := largeTree(200)
+	if got, want := printExpression(tree), sprintfExpression(tree); got != want {
+		t.Fatalf("got %.80q..., want %.80q...", got, want)
+	}
+	if got := printExpression(mustParse(t, "!(A & B) | C")); got != "(!((A & B)) | C)" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func BenchmarkPrintExpression(b *testing.B) {
+	tree := largeTree(2000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		printExpression(tree)
+	}
+}
+
+func BenchmarkSprintfExpression(b *testing.B) {
+	tree := largeTree(2000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sprintfExpression(tree)
+	}
+}
+
+func TestEval(t *testing.T) {
+	assignment := map[string]bool{"p": true, "q": false}
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"p & q", false},
+		{"p & !q", true},
+		{"p | q", true},
+		{"q | q", false},
+		{"!q", true},
+		{"p -> q", false},
+		{"q -> p", true},
+		{"p <-> q", false},
+		{"p <-> !q", true},
+		{"TRUE & p", true},
+		{"FALSE | q", false},
+	}
+	for _, test := range tests {
+		if got, err := Eval(mustParse(t, test.expr), assignment); err != nil || got != test.want {
+			t.Errorf("Eval(%q): got %v, %v, want %v", test.expr, got, err, test.want)
+		}
+	}
+	for _, expr := range []string{"p & r", "!r", "r -> p"} {
+		if _, err := Eval(mustParse(t, expr), assignment); err == nil || !strings.Contains(err.Error(), `"r"`) {
+			t.Errorf("Eval(%q): got %v, want an error naming r", expr, err)
+		}
+	}
+}
+
+func TestPushNegationsEdgeCases(t *testing.T) {
+	tests := map[string]string{
+		"!(A & B)":        "(!(A) | !(B))",
+		"!(A | B)":        "(!(A) & !(B))",
+		"!!!A":            "!(A)",
+		"!!A":             "A",
+		"!(A -> B)":       "(A & !(B))",
+		"!!(A & !B)":      "(A & !(B))",
+		"!(!A | (B & C))": "(A & (!(B) | !(C)))",
+	}
+	for expr, want := range tests {
+		if got := printExpression(PushNegations(mustParse(t, expr))); got != want {
+			t.Errorf("PushNegations(%q): got %q, want %q", expr, got, want)
+		}
+	}
+	for _, expr := range []string{"!(A <-> B)", "!(A -> (B <-> !C))", "!!!(A & B) | C"} {
+		original, pushed := mustParse(t, expr), PushNegations(mustParse(t, expr))
+		for mask := 0; mask < 8; mask++ {
+			values := map[string]bool{"A": mask&1 != 0, "B": mask&2 != 0, "C": mask&4 != 0}
+			want, _ := Eval(original, values)
+			if got, _ := Eval(pushed, values); got != want {
+				t.Errorf("PushNegations(%q) = %s differs under %v", expr, printExpression(pushed), values)
+			}
+		}
+	}
+}
+
+func TestVarAllocatorSharedAcrossConversions(t *testing.T) {
+	alloc := NewVarAllocator()
+	first := TseitinWith(mustParse(t, "(A & B) | C"), alloc)
+	used := alloc.NumVariables()
+	second := TseitinWith(mustParse(t, "(A | D) & !C"), alloc)
+	inFirst := make(map[int]bool)
+	for _, variable := range Variables(first) {
+		inFirst[variable] = true
+	}
+	for _, variable := range Variables(second) {
+		if inFirst[variable] && variable != alloc.Var("A") && variable != alloc.Var("C") {
+			t.Fatalf("variable %d is shared by the conversions but is not A or C", variable)
+		}
+		if !inFirst[variable] && variable <= used {
+			t.Fatalf("variable %d was allocated again", variable)
+		}
+	}
+	if fresh := alloc.Fresh(); fresh != alloc.NumVariables() || fresh <= used {
+		t.Fatalf("Fresh returned %d after %d variables", fresh, used)
+	}
+	vars := alloc.Vars()
+	vars["A"], vars["E"] = 0, 1
+	if alloc.Var("A") == 0 || alloc.Vars()["E"] != 0 {
+		t.Fatal("changing the map returned by Vars changed the allocator")
+	}
+
+	// The combined CNF means both formulas: with !C, (A & B) | C forces A
+	both := append(append(CNF{}, first...), second...)
+	if sat, _ := Solve(both); !sat {
+		t.Fatal("the two formulas hold together")
+	}
+	if sat, _ := Solve(append(both, NodeToCNFWith(mustParse(t, "!A"), alloc)...)); sat {
+		t.Fatal("!A contradicts the two formulas")
+	}
+}
+
+func TestSimplifyConstants(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"!TRUE", "FALSE"},
+		{"(A | !FALSE)", "TRUE"},
+		{"!0", "TRUE"},
+		{"A & 1", "A"},
+		{"A | 0", "A"},
+		{"!!TRUE & B", "B"},
+		{"A -> FALSE", "!(A)"},
+		{"TRUE -> (A & FALSE)", "FALSE"},
+		{"(A <-> !1) | B", "(!(A) | B)"},
+	}
+	for _, test := range tests {
+		if got := printExpression(SimplifyConstants(mustParse(t, test.expr))); got != test.want {
+			t.Errorf("%s: got %s, want %s", test.expr, got, test.want)
+		}
+	}
+	if cnf, _ := NodeToCNF(mustParse(t, "!TRUE")); !reflect.DeepEqual(cnf, CNF{{}}) {
+		t.Errorf("!TRUE: got %v, want the empty clause", cnf)
+	}
+	if cnf, _ := NodeToCNF(mustParse(t, "(A | !FALSE)")); len(cnf) != 0 {
+		t.Errorf("(A | !FALSE): got %v, want no clauses", cnf)
+	}
+	for _, expr := range []string{"(A | !FALSE) & (B -> 0) & (C <-> !1)", "!(A & 1) | (0 <-> B)"} {
+		simplified := SimplifyConstants(mustParse(t, expr))
+		for mask := 0; mask < 8; mask++ {
+			values := map[string]bool{"A": mask&1 != 0, "B": mask&2 != 0, "C": mask&4 != 0}
+			want, _ := Eval(mustParse(t, expr), values)
+			if got, _ := Eval(simplified, values); got != want {
+				t.Fatalf("%s under %v: got %v, want %v", expr, values, got, want)
+			}
+		}
+	}
+}
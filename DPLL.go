@@ -2,8 +2,15 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"math/rand"
 	"os"
+	"os/signal"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -11,47 +18,315 @@ import (
 type Clause []int // A clause is a slice of integers representing literals
 type CNF []Clause // CNF is a conjunction of clauses
 
-// UnitPropagation simplifies the CNF by assigning values for unit clauses
+// Constant literals that a Clause may carry, for instance from encoding TRUE and FALSE.
+// Solving treats a clause containing LiteralTrue as satisfied and ignores LiteralFalse;
+// they are negations of each other, like any other literal.
+const (
+	LiteralTrue  = math.MaxInt32
+	LiteralFalse = -LiteralTrue
+)
+
+// resolveConstants returns the CNF without the clauses containing LiteralTrue and without
+// LiteralFalse literals. It returns cnf itself when no clause carries a constant.
+func resolveConstants(cnf CNF) CNF {
+	found := false
+	for _, clause := range cnf {
+		if containsLiteral(clause, LiteralTrue) || containsLiteral(clause, LiteralFalse) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return cnf
+	}
+	resolved := CNF{}
+	for _, clause := range cnf {
+		if containsLiteral(clause, LiteralTrue) {
+			continue
+		}
+		newClause := Clause{}
+		for _, literal := range clause {
+			if literal != LiteralFalse {
+				newClause = append(newClause, literal)
+			}
+		}
+		resolved = append(resolved, newClause)
+	}
+	return resolved
+}
+
+var (
+	ErrEmptyInput       = errors.New("empty input")            // No formula was given
+	ErrUnbalancedParens = errors.New("unbalanced parentheses") // A '(' or ')' has no partner
+)
+
+// ParseError reports a token that could not be parsed and where it starts in the input
+type ParseError struct {
+	Pos   int    // Byte offset of the token
+	Token string // Offending token
+	Msg   string // What is wrong with it
+	Err   error  // Underlying sentinel such as ErrUnbalancedParens, if any
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("parse error at position %d (%q): %s", e.Pos, e.Token, e.Msg)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// CheckParentheses reports the first unmatched parenthesis as a ParseError wrapping ErrUnbalancedParens
+func CheckParentheses(expr string) error {
+	open := []int{} // Offsets of the parentheses not closed yet
+	for i := 0; i < len(expr); i++ {
+		switch expr[i] {
+		case '(':
+			open = append(open, i)
+		case ')':
+			if len(open) == 0 {
+				return &ParseError{Pos: i, Token: ")", Msg: "no matching '('", Err: ErrUnbalancedParens}
+			}
+			open = open[:len(open)-1]
+		}
+	}
+	if len(open) > 0 {
+		return &ParseError{Pos: open[0], Token: "(", Msg: "no matching ')'", Err: ErrUnbalancedParens}
+	}
+	return nil
+}
+
+// ValidationError reports a clause that does not follow the CNF format
+type ValidationError struct {
+	ClauseIndex int    // Index of the clause, starting at 0
+	Reason      string // Why the clause was rejected
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("clause %d: %s", e.ClauseIndex+1, e.Reason)
+}
+
+// UnitPropagation simplifies the CNF by assigning values for unit clauses.
+// Units wait in a queue and each assignment queues the unit clauses it creates,
+// so the formula is not rescanned to find the next one.
 func UnitPropagation(cnf CNF, assignment map[int]bool) (CNF, bool) {
-	for {
-		unitFound := false
-		for _, clause := range cnf {
-			if len(clause) == 1 { // Found a unit clause
-				unit := clause[0]
-				unitFound = true
-				value := unit > 0
-				variable := abs(unit)
-				assignment[variable] = value
-				cnf = assign(cnf, variable, value)
+	return unitPropagation(cnf, assignment, nil)
+}
+
+// InputUnits forces the unit clauses given in the input, records them in the assignment and
+// simplifies the other clauses by them in a single pass, before any search starts. It reports
+// false at once when two units contradict each other or a clause loses all its literals.
+func InputUnits(cnf CNF, assignment map[int]bool) (CNF, bool) {
+	units := make(map[int]bool)
+	for _, clause := range cnf {
+		if len(clause) != 1 {
+			continue
+		}
+		variable, value := abs(clause[0]), clause[0] > 0
+		if forced, exists := units[variable]; exists && forced != value {
+			return cnf, false // Contradictory units
+		}
+		units[variable] = value
+	}
+	if len(units) == 0 {
+		return cnf, true
+	}
+	simplified := CNF{}
+	for _, clause := range cnf {
+		newClause := Clause{}
+		satisfied := false
+		for _, literal := range clause {
+			value, forced := units[abs(literal)]
+			if !forced {
+				newClause = append(newClause, literal)
+			} else if value == (literal > 0) {
+				satisfied = true
 				break
 			}
 		}
-		if !unitFound {
-			break
+		if satisfied {
+			continue
+		}
+		if len(newClause) == 0 {
+			return cnf, false
 		}
+		simplified = append(simplified, newClause)
 	}
-	for _, clause := range cnf {
+	for variable, value := range units {
+		assignment[variable] = value
+	}
+	return simplified, true
+}
+
+// unitPropagation is UnitPropagation that keeps the occurrence counts of t, if any, up to date.
+// Each unit only visits the clauses containing its variable, found through occurrence lists, and
+// the simplified formula is built once at the end.
+func unitPropagation(cnf CNF, assignment map[int]bool, t *occurrenceTracker) (CNF, bool) {
+	queue := []int{}
+	occurrences := make(map[int][]int) // Literal to the indices of the clauses containing it
+	for i, clause := range cnf {
 		if len(clause) == 0 {
 			return cnf, false // Conflict detected
 		}
+		if len(clause) == 1 { // Found a unit clause
+			queue = append(queue, clause[0])
+		}
+		for j, literal := range clause {
+			if !containsLiteral(clause[:j], literal) {
+				occurrences[literal] = append(occurrences[literal], i)
+			}
+		}
+	}
+	done := make(map[int]bool)
+	satisfied := make([]bool, len(cnf))
+	// remaining returns the literals of the clause whose variables are not assigned yet, keeping
+	// those of the variable keep; an unchanged clause is returned as it is
+	remaining := func(clause Clause, keep int) Clause {
+		reduced := Clause{}
+		for _, literal := range clause {
+			if !done[abs(literal)] || abs(literal) == keep {
+				reduced = append(reduced, literal)
+			}
+		}
+		if len(reduced) == len(clause) {
+			return clause
+		}
+		return reduced
 	}
-	return cnf, true
+	for len(queue) > 0 {
+		unit := queue[0]
+		queue = queue[1:]
+		variable := abs(unit)
+		if done[variable] {
+			continue // Queued twice; a conflicting unit would have emptied its clause
+		}
+		done[variable] = true
+		assignment[variable] = unit > 0
+		for _, i := range occurrences[unit] {
+			if !satisfied[i] {
+				satisfied[i] = true
+				t.removeClause(remaining(cnf[i], variable))
+			}
+		}
+		for _, i := range occurrences[-unit] {
+			if satisfied[i] {
+				continue
+			}
+			clause := remaining(cnf[i], variable)
+			newClause := remaining(cnf[i], 0)
+			if isTautology(newClause) {
+				satisfied[i] = true
+				t.removeClause(clause)
+				continue
+			}
+			if len(newClause) == 0 {
+				return cnf, false // Conflict detected
+			}
+			t.decrement(-unit)
+			if len(newClause) == 1 {
+				queue = append(queue, newClause[0])
+			}
+		}
+	}
+	if len(done) == 0 {
+		return cnf, true
+	}
+	newCNF := CNF{}
+	for i, clause := range cnf {
+		if !satisfied[i] {
+			newCNF = append(newCNF, remaining(clause, 0))
+		}
+	}
+	return newCNF, true
 }
 
-// PureLiteralElimination simplifies CNF by assigning values for pure literals
-func PureLiteralElimination(cnf CNF, assignment map[int]bool) CNF {
-	literalCount := make(map[int]int)
-	for _, clause := range cnf {
-		for _, literal := range clause {
-			literalCount[literal]++
+// isTautology reports whether the clause contains a literal and its negation
+func isTautology(clause Clause) bool {
+	for i, literal := range clause {
+		if containsLiteral(clause[:i], -literal) {
+			return true
 		}
 	}
-	for literal, count := range literalCount {
-		if count > 0 && literalCount[-literal] == 0 { // Pure literal found
-			value := literal > 0
-			variable := abs(literal)
-			assignment[variable] = value
-			cnf = assign(cnf, variable, value)
+	return false
+}
+
+// occurrenceTracker keeps the OccurrenceCounts of a formula up to date while DPLL simplifies it,
+// so pure literals are found among the literals whose count dropped to zero instead of by a rescan.
+// A nil tracker ignores all updates.
+type occurrenceTracker struct {
+	counts  map[int]int
+	journal []int // Decremented literals in order, for undoing
+	zeroed  []int // Literals whose count reached zero since the last pure literal check
+}
+
+// newOccurrenceTracker counts the literals of the CNF; every literal is a pure literal candidate at first
+func newOccurrenceTracker(cnf CNF) *occurrenceTracker {
+	t := &occurrenceTracker{counts: OccurrenceCounts(cnf)}
+	for _, variable := range Variables(cnf) {
+		t.zeroed = append(t.zeroed, variable, -variable)
+	}
+	return t
+}
+
+// decrement records that one clause containing the literal is gone or no longer contains it
+func (t *occurrenceTracker) decrement(literal int) {
+	if t == nil {
+		return
+	}
+	t.counts[literal]--
+	t.journal = append(t.journal, literal)
+	if t.counts[literal] == 0 {
+		t.zeroed = append(t.zeroed, literal)
+	}
+}
+
+// removeClause decrements every distinct literal of a satisfied clause
+func (t *occurrenceTracker) removeClause(clause Clause) {
+	if t == nil {
+		return
+	}
+	for i, literal := range clause {
+		if !containsLiteral(clause[:i], literal) {
+			t.decrement(literal)
+		}
+	}
+}
+
+// undo restores the counts to when the journal had length mark, where no pure literal was pending
+func (t *occurrenceTracker) undo(mark int) {
+	for _, literal := range t.journal[mark:] {
+		t.counts[literal]++
+	}
+	t.journal = t.journal[:mark]
+	t.zeroed = t.zeroed[:0]
+}
+
+// PureLiteralElimination simplifies CNF by assigning values for pure literals
+func PureLiteralElimination(cnf CNF, assignment map[int]bool) CNF {
+	return pureLiterals(cnf, assignment, newOccurrenceTracker(cnf))
+}
+
+// pureLiterals assigns the pure literals among the negations of the literals t saw drop to zero
+// occurrences, including those that become pure as earlier ones remove clauses. Pending literals
+// are handled in increasing order of their variables, so the result never depends on the order
+// in which their counts dropped.
+func pureLiterals(cnf CNF, assignment map[int]bool, t *occurrenceTracker) CNF {
+	for len(t.zeroed) > 0 {
+		pending := t.zeroed
+		t.zeroed = nil
+		sort.Slice(pending, func(i, j int) bool {
+			if abs(pending[i]) != abs(pending[j]) {
+				return abs(pending[i]) < abs(pending[j])
+			}
+			return pending[i] > pending[j]
+		})
+		for _, literal := range pending {
+			if t.counts[literal] == 0 && t.counts[-literal] > 0 { // Pure literal found
+				value := literal < 0
+				variable := abs(literal)
+				assignment[variable] = value
+				cnf = assignTracked(cnf, variable, value, t)
+			}
 		}
 	}
 	return cnf
@@ -59,39 +334,102 @@ func PureLiteralElimination(cnf CNF, assignment map[int]bool) CNF {
 
 // Assign simplifies the CNF given a variable assignment
 func assign(cnf CNF, variable int, value bool) CNF {
+	return assignTracked(cnf, variable, value, nil)
+}
+
+// assignTracked is assign that keeps the occurrence counts of t, if any, up to date
+func assignTracked(cnf CNF, variable int, value bool, t *occurrenceTracker) CNF {
+	falseLiteral := variable
+	if value {
+		falseLiteral = -variable
+	}
 	newCNF := CNF{}
 	for _, clause := range cnf {
-		newClause := Clause{}
-		skipClause := false
-		for _, literal := range clause {
-			if literal == variable && value || literal == -variable && !value {
-				skipClause = true
-				break
-			} else if literal != variable && literal != -variable {
-				newClause = append(newClause, literal)
-			}
+		newClause, satisfied := reduceClause(clause, variable, value)
+		if satisfied {
+			t.removeClause(clause)
+			continue
 		}
-		if !skipClause {
-			newCNF = append(newCNF, newClause)
+		if len(newClause) < len(clause) {
+			t.decrement(falseLiteral)
 		}
+		newCNF = append(newCNF, newClause)
 	}
 	return newCNF
 }
 
-// DPLL implements the main algorithm
+// reduceClause removes the false literals of the variable from the clause, or reports that the clause
+// is satisfied, which includes clauses containing some literal and its negation. Clauses without the
+// variable are returned as they are instead of being copied, so assign and unit propagation only
+// allocate for the clauses they change; such a clause is checked for being a tautology once it is.
+func reduceClause(clause Clause, variable int, value bool) (Clause, bool) {
+	if !containsLiteral(clause, variable) && !containsLiteral(clause, -variable) {
+		return clause, false
+	}
+	newClause := Clause{}
+	for _, literal := range clause {
+		if literal == variable && value || literal == -variable && !value {
+			return nil, true
+		} else if literal != variable && literal != -variable {
+			if containsLiteral(newClause, -literal) {
+				return nil, true // Tautology: a literal and its negation
+			}
+			newClause = append(newClause, literal)
+		}
+	}
+	return newClause, false
+}
+
+// DPLL implements the main algorithm. It records the model in assignment but never modifies cnf:
+// every simplification builds new clauses, so the caller's clauses and their backing arrays are
+// left as they were. The search is deterministic: it branches on the first literal of the first
+// clause and never iterates a map, so the same CNF always yields the same model.
 func DPLL(cnf CNF, assignment map[int]bool) bool {
+	sat, _ := DPLLContext(context.Background(), cnf, assignment)
+	return sat
+}
+
+// DPLLContext is DPLL that gives up with the context's error once ctx is cancelled
+func DPLLContext(ctx context.Context, cnf CNF, assignment map[int]bool) (bool, error) {
+	cnf, ok := InputUnits(resolveConstants(cnf), assignment)
+	if !ok {
+		return false, nil
+	}
+	return dpll(ctx, cnf, assignment, newOccurrenceTracker(cnf))
+}
+
+// dpll is the recursive search behind DPLLContext; t tracks the occurrence counts of cnf.
+// Every node runs pure literal elimination on its own reduced formula, so literals that become
+// pure once a branch removes clauses are assigned there instead of being branched on. Backtracking
+// restores the counts but not assignment: values left by a failed branch only concern variables
+// whose clauses the successful branch satisfies anyway, so they never falsify the final model.
+func dpll(ctx context.Context, cnf CNF, assignment map[int]bool, t *occurrenceTracker) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err // Cancelled: the result is unknown
+	}
+
+	// Trivial cases: no clauses is satisfiable, an empty clause can never be satisfied
+	if len(cnf) == 0 {
+		return true, nil
+	}
+	for _, clause := range cnf {
+		if len(clause) == 0 {
+			return false, nil
+		}
+	}
+
 	// Apply unit propagation
-	cnf, ok := UnitPropagation(cnf, assignment)
+	cnf, ok := unitPropagation(cnf, assignment, t)
 	if !ok {
-		return false // Conflict detected
+		return false, nil // Conflict detected
 	}
 
-	// Apply pure literal elimination
-	cnf = PureLiteralElimination(cnf, assignment)
+	// Apply pure literal elimination, including literals made pure by this branch
+	cnf = pureLiterals(cnf, assignment, t)
 
 	// Check if all clauses are satisfied
 	if len(cnf) == 0 {
-		return true // Satisfiable
+		return true, nil // Satisfiable
 	}
 
 	// Select the next variable to assign (heuristic: first literal in the first clause)
@@ -104,14 +442,16 @@ func DPLL(cnf CNF, assignment map[int]bool) bool {
 	}
 
 	// Try assigning true
+	mark := len(t.journal)
 	assignment[variable] = true
-	if DPLL(assign(cnf, variable, true), assignment) {
-		return true
+	if sat, err := dpll(ctx, assignTracked(cnf, variable, true, t), assignment, t); sat || err != nil {
+		return sat, err
 	}
 
 	// Backtrack and try assigning false
+	t.undo(mark)
 	assignment[variable] = false
-	return DPLL(assign(cnf, variable, false), assignment)
+	return dpll(ctx, assignTracked(cnf, variable, false, t), assignment, t)
 }
 
 // Helper function: absolute value
@@ -122,11 +462,19 @@ func abs(x int) int {
 	return x
 }
 
-// CompleteAssignment ensures all variables have an assignment
+// CompleteAssignment ensures all variables have an assignment. The constants LiteralTrue and
+// LiteralFalse are not variables, so a CNF without variables leaves the assignment as it is; a nil
+// assignment is replaced by a new map.
 func CompleteAssignment(cnf CNF, assignment map[int]bool) map[int]bool {
+	if assignment == nil {
+		assignment = make(map[int]bool)
+	}
 	for _, clause := range cnf {
 		for _, literal := range clause {
 			variable := abs(literal)
+			if variable == LiteralTrue {
+				continue
+			}
 			if _, exists := assignment[variable]; !exists {
 				assignment[variable] = true // Default arbitrary assignment
 			}
@@ -135,79 +483,472 @@ func CompleteAssignment(cnf CNF, assignment map[int]bool) map[int]bool {
 	return assignment
 }
 
-// ParseCNF parses user input into a CNF
-func ParseCNF(input string) CNF {
+// Variables returns the distinct variables of the CNF in increasing order, leaving out the
+// constants LiteralTrue and LiteralFalse
+func Variables(cnf CNF) []int {
+	seen := make(map[int]bool)
+	variables := []int{}
+	for _, clause := range cnf {
+		for _, literal := range clause {
+			variable := abs(literal)
+			if !seen[variable] && variable != LiteralTrue {
+				seen[variable] = true
+				variables = append(variables, variable)
+			}
+		}
+	}
+	sort.Ints(variables)
+	return variables
+}
+
+// NumVariables returns the number of distinct variables in the CNF
+func NumVariables(cnf CNF) int {
+	return len(Variables(cnf))
+}
+
+// NumClauses returns the number of clauses in the CNF
+func NumClauses(cnf CNF) int {
+	return len(cnf)
+}
+
+// Normalize renumbers the variables of the CNF to 1..n in increasing order of their old numbers
+// and returns the renumbered CNF with the mapping from old to new variables
+func Normalize(cnf CNF) (CNF, map[int]int) {
+	mapping := make(map[int]int)
+	for i, variable := range Variables(cnf) {
+		mapping[variable] = i + 1
+	}
+	normalized := make(CNF, len(cnf))
+	for i, clause := range cnf {
+		normalized[i] = make(Clause, len(clause))
+		for j, literal := range clause {
+			if literal < 0 {
+				normalized[i][j] = -mapping[-literal]
+			} else {
+				normalized[i][j] = mapping[literal]
+			}
+		}
+	}
+	return normalized, mapping
+}
+
+// InvertMapping returns the inverse of a variable mapping such as the one returned by Normalize
+func InvertMapping(mapping map[int]int) map[int]int {
+	inverse := make(map[int]int)
+	for from, to := range mapping {
+		inverse[to] = from
+	}
+	return inverse
+}
+
+// TranslateModel renames the variables of a model of a normalized CNF back to the original variables
+func TranslateModel(model map[int]bool, mapping map[int]int) map[int]bool {
+	inverse := InvertMapping(mapping)
+	translated := make(map[int]bool)
+	for variable, value := range model {
+		translated[inverse[variable]] = value
+	}
+	return translated
+}
+
+// Shuffle returns an equivalent CNF with the clauses and the literals of each clause randomly
+// permuted by a generator seeded with seed. The input is not modified.
+func Shuffle(cnf CNF, seed int64) CNF {
+	rng := rand.New(rand.NewSource(seed))
+	shuffled := make(CNF, len(cnf))
+	for i, clause := range cnf {
+		shuffled[i] = append(Clause{}, clause...)
+		rng.Shuffle(len(clause), func(a, b int) {
+			shuffled[i][a], shuffled[i][b] = shuffled[i][b], shuffled[i][a]
+		})
+	}
+	rng.Shuffle(len(shuffled), func(a, b int) {
+		shuffled[a], shuffled[b] = shuffled[b], shuffled[a]
+	})
+	return shuffled
+}
+
+// DuplicateClauseCount returns how many clauses repeat an earlier clause up to the order of their literals
+func DuplicateClauseCount(cnf CNF) int {
+	seen := make(map[string]bool)
+	duplicates := 0
+	for _, clause := range cnf {
+		key := clauseKey(clause...)
+		if seen[key] {
+			duplicates++
+		}
+		seen[key] = true
+	}
+	return duplicates
+}
+
+// OccurrenceCounts returns, for each literal, the number of clauses it appears in
+func OccurrenceCounts(cnf CNF) map[int]int {
+	counts := make(map[int]int)
+	for _, clause := range cnf {
+		seen := make(map[int]bool)
+		for _, literal := range clause {
+			if !seen[literal] {
+				seen[literal] = true
+				counts[literal]++
+			}
+		}
+	}
+	return counts
+}
+
+// VariableFrequency returns, for each variable, its total number of occurrences in either polarity
+func VariableFrequency(cnf CNF) map[int]int {
+	frequency := make(map[int]int)
+	for _, clause := range cnf {
+		for _, literal := range clause {
+			frequency[abs(literal)]++
+		}
+	}
+	return frequency
+}
+
+// Delimiters holds the connectives that separate clauses and literals
+type Delimiters struct {
+	And        string // Separates clauses
+	Or         string // Separates literals within a clause
+	Separators string // Characters that may also separate literals, such as ",\t"
+}
+
+// DefaultDelimiters is the "(1 OR -2) AND (3)" syntax used by the REPL
+var DefaultDelimiters = Delimiters{And: " AND ", Or: " OR "}
+
+// cnfToken is a lexical token of the CNF syntax
+type cnfToken struct {
+	kind string // "(", ")", "AND", "OR" or "literal"
+	text string
+	pos  int // Byte offset in the input
+}
+
+// connectives returns the spellings of each delimiter without surrounding spaces, using the
+// defaults for empty fields. The default delimiters may also be written as & and |.
+func (d Delimiters) connectives() (and, or []string) {
+	defaultAnd, defaultOr := strings.TrimSpace(DefaultDelimiters.And), strings.TrimSpace(DefaultDelimiters.Or)
+	and = []string{strings.TrimSpace(d.And)}
+	if and[0] == "" || and[0] == defaultAnd {
+		and = []string{defaultAnd, "&"}
+	}
+	or = []string{strings.TrimSpace(d.Or)}
+	if or[0] == "" || or[0] == defaultOr {
+		or = []string{defaultOr, "|"}
+	}
+	return and, or
+}
+
+// tokenize splits the input into parentheses, delimiters and literals, ignoring whitespace
+func (d Delimiters) tokenize(input string) []cnfToken {
+	and, or := d.connectives()
+	match := func(i int) (string, string) {
+		for _, kind := range []struct {
+			name  string
+			names []string
+		}{{"AND", and}, {"OR", or}} {
+			for _, name := range kind.names {
+				if strings.HasPrefix(input[i:], name) {
+					return kind.name, name
+				}
+			}
+		}
+		return "", ""
+	}
+	tokens := []cnfToken{}
+	for i := 0; i < len(input); {
+		if kind, text := match(i); kind != "" {
+			tokens = append(tokens, cnfToken{kind: kind, text: text, pos: i})
+			i += len(text)
+			continue
+		}
+		switch c := input[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.IndexByte(d.Separators, c) >= 0:
+			tokens = append(tokens, cnfToken{kind: "OR", text: string(c), pos: i})
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, cnfToken{kind: string(c), text: string(c), pos: i})
+			i++
+		default: // A literal runs up to whitespace, a parenthesis or a delimiter
+			start := i
+			for i++; i < len(input) && !strings.ContainsRune(" \t\n\r()"+d.Separators, rune(input[i])); i++ {
+				if kind, _ := match(i); kind != "" {
+					break
+				}
+			}
+			tokens = append(tokens, cnfToken{kind: "literal", text: input[start:i], pos: start})
+		}
+	}
+	return tokens
+}
+
+// ParseCNF parses user input such as "(1 OR -2) AND (3)" into a CNF.
+// Whitespace around delimiters and parentheses is optional.
+func ParseCNF(input string, delims Delimiters) (CNF, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, ErrEmptyInput
+	}
+	if err := CheckParentheses(input); err != nil {
+		return nil, err
+	}
+	tokens := delims.tokenize(input)
+	tokens = append(tokens, cnfToken{kind: "end", pos: len(input)})
 	cnf := CNF{}
-	clauses := strings.Split(input, " AND ")
-	for _, clause := range clauses {
-		clause = strings.Trim(clause, "() ")
-		literals := strings.Split(clause, " OR ")
+	i := 0
+	for {
+		if tokens[i].kind != "(" {
+			return nil, &ValidationError{ClauseIndex: len(cnf), Reason: "clause must be enclosed in parentheses"}
+		}
+		i++
 		c := Clause{}
-		for _, literal := range literals {
-			num, _ := strconv.Atoi(strings.TrimSpace(literal))
+		for tokens[i].kind != ")" { // "()" is the empty clause, which is false
+			if tokens[i].kind != "literal" {
+				return nil, &ParseError{Pos: tokens[i].pos, Token: tokens[i].text, Msg: "expected a literal"}
+			}
+			num, err := parseLiteral(tokens[i].text, tokens[i].pos)
+			if err != nil {
+				return nil, err
+			}
 			c = append(c, num)
+			i++
+			if tokens[i].kind == ")" {
+				break
+			}
+			if gap := input[tokens[i-1].pos+len(tokens[i-1].text) : tokens[i].pos]; tokens[i].kind == "literal" && strings.ContainsAny(gap, delims.Separators) {
+				continue // Literals separated only by whitespace listed in Separators
+			}
+			if tokens[i].kind != "OR" {
+				return nil, &ParseError{Pos: tokens[i].pos, Token: tokens[i].text, Msg: "expected OR or ')'"}
+			}
+			i++
+			if tokens[i].kind != "literal" { // Every OR needs a literal after it
+				return nil, &ParseError{Pos: tokens[i].pos, Token: tokens[i].text, Msg: "expected a literal after OR"}
+			}
 		}
 		cnf = append(cnf, c)
+		i++
+		if tokens[i].kind == "end" {
+			return cnf, nil
+		}
+		if tokens[i].kind != "AND" {
+			return nil, &ParseError{Pos: tokens[i].pos, Token: tokens[i].text, Msg: "expected AND"}
+		}
+		i++
 	}
-	return cnf
+}
+
+// FormatCNF renders the CNF in the syntax accepted by ParseCNF with the default delimiters.
+// The empty clause is written as "()".
+func FormatCNF(cnf CNF) string {
+	clauses := make([]string, len(cnf))
+	for i, clause := range cnf {
+		literals := make([]string, len(clause))
+		for j, literal := range clause {
+			literals[j] = strconv.Itoa(literal)
+		}
+		clauses[i] = "(" + strings.Join(literals, DefaultDelimiters.Or) + ")"
+	}
+	return strings.Join(clauses, DefaultDelimiters.And)
+}
+
+// Simplify removes repeated literals from each clause and drops clauses containing a literal and its negation.
+// When warn is set, every change is also reported as a ValidationError.
+func Simplify(cnf CNF, warn bool) (CNF, []error) {
+	simplified := CNF{}
+	var warnings []error
+	for i, clause := range cnf {
+		seen := make(map[int]bool)
+		newClause := Clause{}
+		tautology := false
+		for _, literal := range clause {
+			if seen[-literal] {
+				tautology = true
+				break
+			}
+			if !seen[literal] {
+				seen[literal] = true
+				newClause = append(newClause, literal)
+			}
+		}
+		if tautology {
+			if warn {
+				warnings = append(warnings, &ValidationError{ClauseIndex: i, Reason: "clause is always true and was dropped"})
+			}
+			continue
+		}
+		if warn && len(newClause) < len(clause) {
+			warnings = append(warnings, &ValidationError{ClauseIndex: i, Reason: "repeated literals were removed"})
+		}
+		simplified = append(simplified, newClause)
+	}
+	return simplified, warnings
+}
+
+// parseLiteral converts a token at byte offset pos into a non-zero literal
+func parseLiteral(token string, pos int) (int, error) {
+	num, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, &ParseError{Pos: pos, Token: token, Msg: "literal must be an integer"}
+	}
+	if num == 0 {
+		return 0, &ParseError{Pos: pos, Token: token, Msg: "literal must be non-zero"}
+	}
+	return num, nil
 }
 
 // ValidateCNF ensures the formula is in correct CNF format
-func ValidateCNF(input string) bool {
-	clauses := strings.Split(input, " AND ")
-	for _, clause := range clauses {
-		clause = strings.TrimSpace(clause)
-		if len(clause) < 2 || clause[0] != '(' || clause[len(clause)-1] != ')' {
-			return false
+func ValidateCNF(input string, delims Delimiters) error {
+	_, err := ParseCNF(input, delims)
+	return err
+}
+
+// readFormula reads lines until they form a complete formula or a blank line is entered,
+// printing the continuation prompt before each further line
+func readFormula(reader *bufio.Reader, continuation string) (string, error) {
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if line != "" {
+			lines = append(lines, line)
+		}
+		input := strings.Join(lines, " ")
+		if line == "" || err != nil || strings.ToLower(input) == "exit" || ValidateCNF(input, DefaultDelimiters) == nil {
+			return input, err
+		}
+		fmt.Print(continuation) // Formula continues on the next line
+	}
+}
+
+// readBatchFormula reads the next formula of a batch: one non-blank line, joined with the
+// following lines only while a parenthesis is left open, so an invalid line never swallows
+// the formulas after it
+func readBatchFormula(reader *bufio.Reader) (string, error) {
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if line != "" {
+			lines = append(lines, line)
 		}
-		literals := strings.Split(clause[1:len(clause)-1], " OR ")
-		if len(literals) == 0 {
-			return false
+		input := strings.Join(lines, " ")
+		var parseErr *ParseError
+		unclosed := errors.As(CheckParentheses(input), &parseErr) && parseErr.Token == "("
+		if err != nil || input != "" && (!unclosed || line == "") {
+			return input, err
 		}
-		for _, literal := range literals {
-			literal = strings.TrimSpace(literal)
-			if _, err := strconv.Atoi(literal); err != nil {
-				return false
+	}
+}
+
+// batchCommand solves every formula read from r, each on its own line or spread over several
+// lines while its parentheses are open, and writes one result per formula prefixed by its index.
+// It returns 1 if some formula was invalid and 0 otherwise.
+func batchCommand(r io.Reader, w io.Writer) int {
+	reader := bufio.NewReader(r)
+	status := 0
+	index := 1
+	for {
+		input, err := readBatchFormula(reader)
+		if input != "" {
+			cnf, parseErr := ParseCNF(input, DefaultDelimiters)
+			assignment := make(map[int]bool)
+			switch {
+			case parseErr != nil:
+				fmt.Fprintf(w, "%d: INVALID %v\n", index, parseErr)
+				status = 1
+			case DPLL(cnf, assignment):
+				fmt.Fprintf(w, "%d: SATISFIABLE with assignment: %v\n", index, CompleteAssignment(cnf, assignment))
+			default:
+				fmt.Fprintf(w, "%d: UNSATISFIABLE\n", index)
 			}
+			index++
+		}
+		if err != nil {
+			return status
 		}
 	}
-	return true
 }
 
 func main() {
+	// "convert [expression]" prints a propositional formula in CNF instead of starting the solver
+	if len(os.Args) > 1 && os.Args[1] == "convert" {
+		convertExample(strings.Join(os.Args[2:], " "))
+		return
+	}
+	// "solve [file]" solves a DIMACS CNF file (or standard input) and prints the result in SAT competition format
+	if len(os.Args) > 1 && os.Args[1] == "solve" {
+		os.Exit(solveCommand(os.Args[2:], os.Stdout))
+	}
+	// "icnf [file]" solves an incremental iCNF file (or standard input) under each of its assumption sets
+	if len(os.Args) > 1 && os.Args[1] == "icnf" {
+		os.Exit(icnfCommand(os.Args[2:], os.Stdout))
+	}
+	// "batch" solves every formula on standard input and prints numbered results until EOF
+	if len(os.Args) > 1 && os.Args[1] == "batch" {
+		os.Exit(batchCommand(os.Stdin, os.Stdout))
+	}
+
+	// "-explain" names the clauses that conflict whenever a formula is unsatisfiable
+	explain := len(os.Args) > 1 && os.Args[1] == "-explain"
+
 	reader := bufio.NewReader(os.Stdin)
 
 	fmt.Println("Welcome to the Interactive DPLL SAT Solver")
 	fmt.Println("Input your CNF formula using the format: (1 OR -2) AND (-1 OR 3) AND (2 OR -3)")
+	fmt.Println("Long formulas may span several lines; enter a blank line to finish early.")
 	fmt.Println("Type 'exit' to quit the program.")
 
 	for {
 		fmt.Print("\nEnter your formula: ")
-		input, _ := reader.ReadString('\n')
-		input = strings.TrimSpace(input)
+		input, err := readFormula(reader, "... ")
 
 		// Check for exit condition
-		if strings.ToLower(input) == "exit" {
+		if strings.ToLower(input) == "exit" || err != nil && input == "" {
 			fmt.Println("Exiting the program. Goodbye!")
 			break
 		}
 
 		// Validate input
-		if !ValidateCNF(input) {
-			fmt.Println("Invalid CNF format. Please use the format: (literal1 OR literal2) AND (literal3 OR ... )")
+		if err = ValidateCNF(input, DefaultDelimiters); err != nil {
+			fmt.Println("Invalid CNF format:", err)
+			var parseErr *ParseError
+			if errors.As(err, &parseErr) { // Point at the offending token
+				fmt.Println("  " + input)
+				fmt.Println("  " + strings.Repeat(" ", parseErr.Pos) + "^")
+			}
+			fmt.Println("Please use the format: (literal1 OR literal2) AND (literal3 OR ... )")
 			continue
 		}
 
 		// Parse input into CNF
-		cnf := ParseCNF(input)
+		cnf, err := ParseCNF(input, DefaultDelimiters)
+		if err != nil {
+			fmt.Println("Invalid CNF format:", err)
+			continue
+		}
+		simplified, warnings := Simplify(cnf, true)
+		for _, warning := range warnings {
+			fmt.Println("Warning:", warning)
+		}
 
-		// Solve using DPLL
+		// Solve using DPLL; Ctrl+C stops the search and returns to the prompt
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 		assignment := make(map[int]bool)
-		if DPLL(cnf, assignment) {
+		sat, err := DPLLContext(ctx, simplified, assignment)
+		stop() // Ctrl+C at the prompt exits the program again
+		if err != nil {
+			fmt.Println("UNKNOWN (interrupted)")
+		} else if sat {
 			assignment = CompleteAssignment(cnf, assignment)
 			fmt.Println("SATISFIABLE with assignment:", assignment)
 		} else {
 			fmt.Println("UNSATISFIABLE")
+			if explain {
+				fmt.Println(ExplainUnsat(simplified))
+			}
 		}
 	}
 }
@@ -0,0 +1,643 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestBatchCommand(t *testing.T) {
+	tests := []struct {
+		input, want string
+		status      int
+	}{
+		{
+			input:  "(1 OR 2) AND (-1)\n(1) AND (-1)\n(1 OR -1)\n",
+			want:   "1: SATISFIABLE with assignment: map[1:false 2:true]\n2: UNSATISFIABLE\n3: SATISFIABLE with assignment: map[1:true]\n",
+			status: 0,
+		},
+		{
+			input:  "(1 OR -2)\n(1 OR x)\n(2)\n(-1) AND (1)\n",
+			want:   "1: SATISFIABLE with assignment: map[1:true 2:true]\n2: INVALID parse error at position 6 (\"x\"): literal must be an integer\n3: SATISFIABLE with assignment: map[2:true]\n4: UNSATISFIABLE\n",
+			status: 1,
+		},
+		{
+			input:  "\n(1 OR\n2) AND (-1)\n\n(3)",
+			want:   "1: SATISFIABLE with assignment: map[1:false 2:true]\n2: SATISFIABLE with assignment: map[3:true]\n",
+			status: 0,
+		},
+	}
+	for _, test := range tests {
+		var out bytes.Buffer
+		status := batchCommand(strings.NewReader(test.input), &out)
+		if out.String() != test.want || status != test.status {
+			t.Errorf("batch %q:\n%s(status %d), want\n%s(status %d)", test.input, out.String(), status, test.want, test.status)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		pos   int
+		token string
+	}{
+		{"(1 OR x) AND (2)", 6, "x"},
+		{"(1 OR 2) AND  (3 OR zz)", 20, "zz"},
+		{"(1 OR 2) AND (3 OR q)", 19, "q"},
+		{"(1 OR 2", 0, "("},
+		{"(1 OR 2))", 8, ")"},
+		{"(1 OR )", 6, ")"},
+		{"(1 OR OR 2)", 6, "OR"},
+	}
+	for _, test := range tests {
+		_, err := ParseCNF(test.input, DefaultDelimiters)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) || parseErr.Pos != test.pos || parseErr.Token != test.token {
+			t.Errorf("ParseCNF(%q): got %v, want a ParseError for %q at %d", test.input, err, test.token, test.pos)
+		}
+	}
+
+	var validationErr *ValidationError
+	if err := ValidateCNF("(1 OR 2) AND 3", DefaultDelimiters); !errors.As(err, &validationErr) || validationErr.ClauseIndex != 1 {
+		t.Errorf("got %v, want a ValidationError for clause 1", err)
+	}
+	if err := ValidateCNF("  ", DefaultDelimiters); !errors.Is(err, ErrEmptyInput) {
+		t.Errorf("got %v, want ErrEmptyInput", err)
+	}
+	if err := ValidateCNF("(1 OR 2", DefaultDelimiters); !errors.Is(err, ErrUnbalancedParens) {
+		t.Errorf("got %v, want ErrUnbalancedParens", err)
+	}
+	if err := ValidateCNF("(1 OR -2) AND (3)", DefaultDelimiters); err != nil {
+		t.Errorf("valid formula: got %v", err)
+	}
+}
+
+func TestCheckParentheses(t *testing.T) {
+	tests := []struct {
+		input string
+		pos   int // -1 when balanced
+	}{
+		{"(1 OR 2) AND (3)", -1},
+		{"((a & b) | c)", -1},
+		{")", 0},
+		{"(1 OR 2))", 8},
+		{"(1 OR 2) AND (3", 13},
+		{"((1 OR 2) AND (3)", 0},
+		{"a & b) | (c", 5},
+	}
+	for _, test := range tests {
+		err := CheckParentheses(test.input)
+		if test.pos == -1 {
+			if err != nil {
+				t.Errorf("CheckParentheses(%q): got %v, want nil", test.input, err)
+			}
+			continue
+		}
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) || !errors.Is(err, ErrUnbalancedParens) || parseErr.Pos != test.pos {
+			t.Errorf("CheckParentheses(%q): got %v, want an unmatched parenthesis at %d", test.input, err, test.pos)
+		}
+	}
+	if _, err := IsTautologyCEGAR("(a | !a"); !errors.Is(err, ErrUnbalancedParens) {
+		t.Errorf("parseExpression callers: got %v, want ErrUnbalancedParens", err)
+	}
+}
+
+func TestReadFormula(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("(1 OR -2) AND\n(-1 OR 3)\n(5)\n"))
+	if input, err := readFormula(reader, ""); err != nil || input != "(1 OR -2) AND (-1 OR 3)" {
+		t.Fatalf("got %q, %v", input, err)
+	}
+	if input, err := readFormula(reader, ""); err != nil || input != "(5)" {
+		t.Fatalf("got %q, %v", input, err)
+	}
+	reader = bufio.NewReader(strings.NewReader("(1 OR\n\n(5)\n"))
+	if input, _ := readFormula(reader, ""); input != "(1 OR" {
+		t.Fatalf("a blank line should end the formula, got %q", input)
+	}
+}
+
+func TestParseCNFDelimiters(t *testing.T) {
+	want := CNF{{1, -2}, {3, 4}}
+	for _, test := range []struct {
+		input  string
+		delims Delimiters
+	}{
+		{"(1 OR -2) AND (3 OR 4)", DefaultDelimiters},
+		{"(1 OR -2) AND (3 OR 4)", Delimiters{}},
+		{"(1 | -2) &  (3|4)", Delimiters{And: "&", Or: "|"}},
+		{"(1 v -2) ^ (3 v 4)", Delimiters{And: " ^ ", Or: " v "}},
+	} {
+		got, err := ParseCNF(test.input, test.delims)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseCNF(%q, %+v): got %v, %v, want %v", test.input, test.delims, got, err, want)
+		}
+	}
+	if err := ValidateCNF("(1 | -2) & (3|x)", Delimiters{And: "&", Or: "|"}); err == nil {
+		t.Error("an invalid literal was accepted with custom delimiters")
+	}
+}
+
+func TestParseCNFSpacing(t *testing.T) {
+	want := CNF{{1, -2}, {-1, 3}}
+	for _, input := range []string{
+		"(1 OR -2) AND (-1 OR 3)",
+		"(1 OR -2)AND(-1 OR 3)",
+		"  ( 1   OR  -2 )  AND   ( -1 OR 3 )  ",
+		"(1 OR -2)\tAND\n(-1 OR 3)",
+	} {
+		got, err := ParseCNF(input, DefaultDelimiters)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseCNF(%q): got %v, %v, want %v", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"(1|-2)&(-1|3)", "( 1 | -2 ) & ( -1 | 3 )"} {
+		got, err := ParseCNF(input, Delimiters{And: "&", Or: "|"})
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseCNF(%q): got %v, %v, want %v", input, got, err, want)
+		}
+	}
+}
+
+func TestParseCNFSeparators(t *testing.T) {
+	want := CNF{{1, -2, 3}, {-1, 4}}
+	delims := Delimiters{And: "AND", Or: "OR", Separators: ",\t"}
+	for _, input := range []string{
+		"(1 OR -2 OR 3) AND (-1 OR 4)",
+		"(1\t-2\t3) AND (-1\t4)",
+		"(1,-2,3) AND (-1, 4)",
+		"(1, -2 OR 3) AND (-1\t4)",
+	} {
+		got, err := ParseCNF(input, delims)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseCNF(%q): got %v, %v, want %v", input, got, err, want)
+		}
+	}
+	if _, err := ParseCNF("(1 -2)", delims); err == nil {
+		t.Error("literals separated by a space not listed in Separators were accepted")
+	}
+	if _, err := ParseCNF("(1,-2)", DefaultDelimiters); err == nil {
+		t.Error("a comma was accepted without being listed in Separators")
+	}
+}
+
+func TestFormatCNFRoundTrip(t *testing.T) {
+	for _, cnf := range []CNF{
+		{{1, -2}, {3}},
+		{{-1, -2, -3}, {}, {4, 1}},
+		{{}},
+		{{2, 2, -7}},
+	} {
+		formatted := FormatCNF(cnf)
+		got, err := ParseCNF(formatted, DefaultDelimiters)
+		if err != nil || !reflect.DeepEqual(got, cnf) {
+			t.Errorf("ParseCNF(FormatCNF(%v)) = %v, %v via %q", cnf, got, err, formatted)
+		}
+	}
+	if got := FormatCNF(CNF{{1, -2}, {}}); got != "(1 OR -2) AND ()" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestSimplify(t *testing.T) {
+	simplified, warnings := Simplify(CNF{{1, 1}, {1, -1}, {2, 3, 2}}, true)
+	if want := (CNF{{1}, {2, 3}}); !reflect.DeepEqual(simplified, want) {
+		t.Fatalf("got %v, want %v", simplified, want)
+	}
+	var validationErr *ValidationError
+	if len(warnings) != 3 || !errors.As(warnings[1], &validationErr) || validationErr.ClauseIndex != 1 {
+		t.Fatalf("got warnings %v", warnings)
+	}
+	if _, warnings := Simplify(CNF{{1, 1}}, false); warnings != nil {
+		t.Fatalf("warnings without warn: %v", warnings)
+	}
+}
+
+func TestDuplicateClauseCount(t *testing.T) {
+	cnf := CNF{{1, -2}, {3}, {-2, 1}, {1, -2}, {2, 3}}
+	if got := DuplicateClauseCount(cnf); got != 2 {
+		t.Errorf("three copies of (1 OR -2): got %d duplicates, want 2", got)
+	}
+	if got := DuplicateClauseCount(CNF{{1, 2}, {1, -2}, {}}); got != 0 {
+		t.Errorf("distinct clauses: got %d duplicates, want 0", got)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	cnf := CNF{{9, -1}, {5}, {-9, -5, 1}}
+	normalized, mapping := Normalize(cnf)
+	if want := (CNF{{3, -1}, {2}, {-3, -2, 1}}); !reflect.DeepEqual(normalized, want) {
+		t.Fatalf("got %v, want %v", normalized, want)
+	}
+	if want := map[int]int{1: 1, 5: 2, 9: 3}; !reflect.DeepEqual(mapping, want) {
+		t.Fatalf("mapping: got %v, want %v", mapping, want)
+	}
+	if inverse := InvertMapping(mapping); !reflect.DeepEqual(inverse, map[int]int{1: 1, 2: 5, 3: 9}) {
+		t.Fatalf("inverse: got %v", inverse)
+	}
+	model := map[int]bool{}
+	if !DPLL(normalized, model) {
+		t.Fatal("the normalized formula is satisfiable")
+	}
+	if original := TranslateModel(model, mapping); !satisfies(cnf, original) {
+		t.Fatalf("the translated model %v does not satisfy %v", original, cnf)
+	}
+}
+
+func TestTautologyDroppedDuringSearch(t *testing.T) {
+	// Resolving (1 OR 2 OR 3) and (-1 OR -2) on 1 gives the tautology (2 OR 3 OR -2)
+	resolvent := Clause{2, 3, -2}
+	if got := assign(CNF{resolvent, {1, 4}}, 3, false); !reflect.DeepEqual(got, CNF{{1, 4}}) {
+		t.Errorf("assign: got %v, want the tautology dropped", got)
+	}
+	assignment := map[int]bool{}
+	got, ok := UnitPropagation(CNF{{-3}, resolvent, {1, 4}}, assignment)
+	if !ok || !reflect.DeepEqual(got, CNF{{1, 4}}) || assignment[3] {
+		t.Errorf("UnitPropagation: got %v, %v, %v, want the tautology dropped", got, ok, assignment)
+	}
+	if _, assigned := assignment[2]; assigned {
+		t.Errorf("the tautology forced variable 2: %v", assignment)
+	}
+}
+
+func TestConstantLiterals(t *testing.T) {
+	cnf := CNF{{1, LiteralTrue}, {2, LiteralFalse}, {-2, 3}}
+	if got, want := resolveConstants(cnf), (CNF{{2}, {-2, 3}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want the true clause dropped and the false literal removed", got)
+	}
+	assignment := map[int]bool{}
+	if !DPLL(cnf, assignment) || !assignment[2] || !assignment[3] {
+		t.Errorf("got %v, want 2 and 3 forced", assignment)
+	}
+	if _, assigned := assignment[LiteralTrue]; assigned {
+		t.Errorf("a constant was assigned like a variable: %v", assignment)
+	}
+	if DPLL(CNF{{1, 2}, {LiteralFalse, LiteralFalse}}, map[int]bool{}) {
+		t.Error("a clause of false constants was satisfied")
+	}
+	s := NewSolver()
+	s.AddClause(Clause{LiteralTrue, -1})
+	s.AddClause(Clause{LiteralFalse})
+	if s.Solve() {
+		t.Error("Solver: a clause of false constants was satisfied")
+	}
+}
+
+func TestShuffle(t *testing.T) {
+	for seed := int64(0); seed < 100; seed++ {
+		cnf := random3SAT(8, 20+int(seed%20), seed)
+		before := fmt.Sprint(cnf)
+		shuffled := Shuffle(cnf, seed)
+		if fmt.Sprint(cnf) != before {
+			t.Fatalf("seed %d: the input was modified", seed)
+		}
+		sorted := func(cnf CNF) []string {
+			keys := []string{}
+			for _, clause := range cnf {
+				keys = append(keys, clauseKey(clause...))
+			}
+			sort.Strings(keys)
+			return keys
+		}
+		if !reflect.DeepEqual(sorted(shuffled), sorted(cnf)) {
+			t.Fatalf("seed %d: %v is not a permutation of %v", seed, shuffled, cnf)
+		}
+		sat, _ := Solve(cnf)
+		shuffledSat, model := Solve(shuffled)
+		if sat != shuffledSat || shuffledSat && len(UnsatisfiedClauses(cnf, model)) > 0 {
+			t.Fatalf("seed %d: original %v, shuffled %v with model %v", seed, sat, shuffledSat, model)
+		}
+	}
+	if a, b := Shuffle(random3SAT(8, 30, 1), 5), Shuffle(random3SAT(8, 30, 1), 5); !reflect.DeepEqual(a, b) {
+		t.Fatal("the same seed gave different orders")
+	}
+}
+
+func TestDPLLTrivial(t *testing.T) {
+	assignment := map[int]bool{}
+	if !DPLL(CNF{}, assignment) || len(assignment) != 0 {
+		t.Fatalf("CNF{} is satisfiable by the empty model, got %v", assignment)
+	}
+	if DPLL(CNF{{1, 2}, Clause{}}, map[int]bool{}) {
+		t.Fatal("a formula with an empty clause is unsatisfiable")
+	}
+	if DPLL(CNF{Clause{}}, map[int]bool{}) {
+		t.Fatal("CNF{Clause{}} is unsatisfiable")
+	}
+}
+
+func TestVariableCounts(t *testing.T) {
+	cnf := CNF{{7, -2}, {}, {-7, 40, 2}}
+	if got := Variables(cnf); !reflect.DeepEqual(got, []int{2, 7, 40}) {
+		t.Errorf("Variables: got %v", got)
+	}
+	if got := NumVariables(cnf); got != 3 {
+		t.Errorf("NumVariables: got %d", got)
+	}
+	if got := NumClauses(cnf); got != 3 {
+		t.Errorf("NumClauses: got %d", got)
+	}
+	if got := Variables(CNF{{}}); len(got) != 0 {
+		t.Errorf("an empty clause has no variables, got %v", got)
+	}
+}
+
+func TestOccurrenceStatistics(t *testing.T) {
+	cnf := CNF{{1, -2, 1}, {-1, -2}, {2, 3}, {}}
+	if got, want := OccurrenceCounts(cnf), map[int]int{1: 1, -1: 1, -2: 2, 2: 1, 3: 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("OccurrenceCounts: got %v, want %v", got, want)
+	}
+	if got, want := VariableFrequency(cnf), map[int]int{1: 3, 2: 3, 3: 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("VariableFrequency: got %v, want %v", got, want)
+	}
+}
+
+func TestDPLLContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sat, err := DPLLContext(ctx, CNF{{1, 2}, {-1}}, map[int]bool{})
+	if sat || !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, %v, want the cancellation error", sat, err)
+	}
+
+	// A search cancelled part way reports the error rather than an answer, and stops at once
+	counting := &countingContext{Context: context.Background(), cancelAfter: 5}
+	sat, err = DPLLContext(counting, random3SAT(200, 852, 1), map[int]bool{})
+	if sat || !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, %v, want the cancellation error", sat, err)
+	}
+	if counting.nodes != counting.cancelAfter+1 {
+		t.Fatalf("the search went on for %d nodes after the cancellation", counting.nodes-counting.cancelAfter-1)
+	}
+}
+
+// countingContext counts the search nodes of DPLLContext, which checks Err once per node, and
+// reports a cancellation after cancelAfter of them when it is set
+type countingContext struct {
+	context.Context
+	nodes       int
+	cancelAfter int
+}
+
+func (c *countingContext) Err() error {
+	c.nodes++
+	if c.cancelAfter > 0 && c.nodes > c.cancelAfter {
+		return context.Canceled
+	}
+	return c.Context.Err()
+}
+
+func TestMidSearchPureLiteral(t *testing.T) {
+	// 2 is pure at the root. Once the search sets 1, variable 3 only occurs positively and 4 only
+	// negatively, so both are assigned there instead of being branched on.
+	cnf := CNF{{1, 2}, {-1, 3, 4}, {1, -3}, {-4, 5}, {-5, -4}}
+	ctx := &countingContext{Context: context.Background()}
+	assignment := map[int]bool{}
+	if sat, err := DPLLContext(ctx, cnf, assignment); !sat || err != nil {
+		t.Fatalf("got %v, %v", sat, err)
+	}
+	if ctx.nodes != 2 {
+		t.Errorf("got %d search nodes, want the root and the branch on 1", ctx.nodes)
+	}
+	if want := map[int]bool{1: true, 2: true, 3: true, 4: false}; !reflect.DeepEqual(assignment, want) {
+		t.Errorf("got %v, want %v", assignment, want)
+	}
+
+	// The occurrence counts seen by the branch are those of its reduced formula
+	tracker := newOccurrenceTracker(cnf)
+	reduced := pureLiterals(cnf, map[int]bool{}, tracker)
+	reduced = assignTracked(reduced, 1, true, tracker)
+	if tracker.counts[-3] != 0 || tracker.counts[3] != 1 || tracker.counts[4] != 1 {
+		t.Errorf("counts after 1 = true: %v", tracker.counts)
+	}
+	if reduced = pureLiterals(reduced, map[int]bool{}, tracker); len(reduced) != 0 {
+		t.Errorf("got %v, want every clause satisfied by pure literals", reduced)
+	}
+}
+
+func TestInputUnits(t *testing.T) {
+	assignment := map[int]bool{}
+	cnf, ok := InputUnits(CNF{{1}, {-1, 2, 3}, {1, 4}, {-2}}, assignment)
+	if want := (CNF{{3}}); !ok || !reflect.DeepEqual(cnf, want) {
+		t.Fatalf("got %v, %v, want %v", cnf, ok, want)
+	}
+	if want := map[int]bool{1: true, 2: false}; !reflect.DeepEqual(assignment, want) {
+		t.Fatalf("got %v, want %v", assignment, want)
+	}
+
+	assignment = map[int]bool{}
+	if _, ok := InputUnits(CNF{{1}, {2, 3}, {-1}}, assignment); ok || len(assignment) != 0 {
+		t.Fatalf("contradictory units: got %v, %v", ok, assignment)
+	}
+	ctx := &countingContext{Context: context.Background()}
+	if sat, err := DPLLContext(ctx, CNF{{1}, {2, 3}, {-1}}, map[int]bool{}); sat || err != nil || ctx.nodes != 0 {
+		t.Fatalf("got %v, %v after %d search nodes, want UNSAT before the search", sat, err, ctx.nodes)
+	}
+}
+
+// implicationChain returns x1 and the clauses x_i -> x_i+1 up to x_n, listed backwards so that
+// each unit is found only after the whole formula has been scanned
+func implicationChain(n int) CNF {
+	cnf := CNF{}
+	for i := n - 1; i >= 1; i-- {
+		cnf = append(cnf, Clause{-i, i + 1})
+	}
+	return append(cnf, Clause{1})
+}
+
+// rescanPropagation is unit propagation that rescans the formula for the next unit clause,
+// as UnitPropagation did before it kept a queue
+func rescanPropagation(cnf CNF, assignment map[int]bool) (CNF, bool) {
+	for {
+		unit := 0
+		for _, clause := range cnf {
+			if len(clause) == 0 {
+				return cnf, false
+			}
+			if len(clause) == 1 {
+				unit = clause[0]
+				break
+			}
+		}
+		if unit == 0 {
+			return cnf, true
+		}
+		assignment[abs(unit)] = unit > 0
+		cnf = assign(cnf, abs(unit), unit > 0)
+	}
+}
+
+func TestUnitPropagationChain(t *testing.T) {
+	cnf := append(implicationChain(50), Clause{-50, 7}, Clause{-20, -30, 60})
+	queued, rescanned := map[int]bool{}, map[int]bool{}
+	rest, ok := UnitPropagation(cnf, queued)
+	want, wantOK := rescanPropagation(cnf, rescanned)
+	if ok != wantOK || !reflect.DeepEqual(queued, rescanned) || !reflect.DeepEqual(rest, want) {
+		t.Fatalf("got %v, %v, %v, want %v, %v, %v", rest, ok, queued, want, wantOK, rescanned)
+	}
+	if _, ok := UnitPropagation(append(implicationChain(50), Clause{-50}), map[int]bool{}); ok {
+		t.Fatal("the chain contradicts -50")
+	}
+}
+
+func BenchmarkUnitPropagationChain(b *testing.B) {
+	cnf := implicationChain(2000)
+	for i := 0; i < b.N; i++ {
+		UnitPropagation(cnf, map[int]bool{})
+	}
+}
+
+func BenchmarkRescanPropagationChain(b *testing.B) {
+	cnf := implicationChain(2000)
+	for i := 0; i < b.N; i++ {
+		rescanPropagation(cnf, map[int]bool{})
+	}
+}
+
+// rescanDPLL is the search of DPLL recounting the literal occurrences at every node to find pure
+// literals, as it did before the counts were kept up to date incrementally
+func rescanDPLL(cnf CNF, assignment map[int]bool) bool {
+	cnf, ok := UnitPropagation(cnf, assignment)
+	if !ok {
+		return false
+	}
+	cnf = PureLiteralElimination(cnf, assignment)
+	if len(cnf) == 0 {
+		return true
+	}
+	for _, clause := range cnf {
+		if len(clause) == 0 {
+			return false
+		}
+	}
+	variable := abs(cnf[0][0])
+	assignment[variable] = true
+	if rescanDPLL(assign(cnf, variable, true), assignment) {
+		return true
+	}
+	assignment[variable] = false
+	return rescanDPLL(assign(cnf, variable, false), assignment)
+}
+
+func TestIncrementalPureLiterals(t *testing.T) {
+	for seed := int64(0); seed < 30; seed++ {
+		cnf := random3SAT(30, 120+int(seed%3)*10, seed)
+		incremental, rescanned := map[int]bool{}, map[int]bool{}
+		sat := DPLL(cnf, incremental)
+		if want := rescanDPLL(cnf, rescanned); sat != want {
+			t.Fatalf("seed %d: got %v, want %v", seed, sat, want)
+		}
+		if sat && len(UnsatisfiedClauses(cnf, CompleteAssignment(cnf, incremental))) > 0 {
+			t.Fatalf("seed %d: the model %v falsifies a clause", seed, incremental)
+		}
+	}
+}
+
+func BenchmarkIncrementalPureLiterals(b *testing.B) {
+	cnf := random3SAT(80, 300, 1)
+	for i := 0; i < b.N; i++ {
+		DPLL(cnf, map[int]bool{})
+	}
+}
+
+func BenchmarkRescanPureLiterals(b *testing.B) {
+	cnf := random3SAT(80, 300, 1)
+	for i := 0; i < b.N; i++ {
+		rescanDPLL(cnf, map[int]bool{})
+	}
+}
+
+// copyingAssign is assign that copies every clause it keeps, as it did before untouched clauses
+// were shared
+func copyingAssign(cnf CNF, variable int, value bool) CNF {
+	newCNF := CNF{}
+	for _, clause := range cnf {
+		newClause := Clause{}
+		satisfied := false
+		for _, literal := range clause {
+			if literal == variable && value || literal == -variable && !value || containsLiteral(newClause, -literal) {
+				satisfied = true
+				break
+			} else if literal != variable && literal != -variable {
+				newClause = append(newClause, literal)
+			}
+		}
+		if !satisfied {
+			newCNF = append(newCNF, newClause)
+		}
+	}
+	return newCNF
+}
+
+func TestAssignSharesUntouchedClauses(t *testing.T) {
+	cnf := random3SAT(50, 200, 3)
+	shared, copied := cnf, cnf
+	for variable := 1; variable <= 10; variable++ {
+		shared = assign(shared, variable, variable%2 == 0)
+		copied = copyingAssign(copied, variable, variable%2 == 0)
+	}
+	if !reflect.DeepEqual(shared, copied) {
+		t.Fatalf("got %v, want %v", shared, copied)
+	}
+	untouched := Clause{7, 8}
+	if got := assign(CNF{untouched, {1, 2}}, 1, false); &got[0][0] != &untouched[0] {
+		t.Fatal("a clause without the variable was copied")
+	}
+}
+
+// assignSequence assigns the first 40 variables in turn, as a branch of the search does, so most
+// clauses are kept many times without containing the assigned variable
+func assignSequence(b *testing.B, assign func(CNF, int, bool) CNF) {
+	cnf := random3SAT(400, 1600, 1)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		reduced := cnf
+		for variable := 1; variable <= 40; variable++ {
+			reduced = assign(reduced, variable, variable%2 == 0)
+		}
+	}
+}
+
+func BenchmarkAssignShared(b *testing.B) {
+	assignSequence(b, assign)
+}
+
+func BenchmarkAssignCopying(b *testing.B) {
+	assignSequence(b, copyingAssign)
+}
+
+// random3SAT returns m random clauses of three distinct variables out of n
+func random3SAT(n, m int, seed int64) CNF {
+	rng := rand.New(rand.NewSource(seed))
+	cnf := CNF{}
+	for i := 0; i < m; i++ {
+		clause := Clause{}
+		for _, variable := range rng.Perm(n)[:3] {
+			if rng.Intn(2) == 0 {
+				clause = append(clause, variable+1)
+			} else {
+				clause = append(clause, -(variable + 1))
+			}
+		}
+		cnf = append(cnf, clause)
+	}
+	return cnf
+}
+
+// satisfies reports whether the model makes every clause of the formula true
+func satisfies(cnf CNF, model map[int]bool) bool {
+	for _, clause := range cnf {
+		if !clauseSatisfied(clause, model) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,154 @@
+package main
+
+// AIGLit refers to an AIG node as twice its index, plus one when the edge is inverted
+type AIGLit int
+
+const (
+	AIGFalse AIGLit = 0 // Node 0 is the constant false
+	AIGTrue  AIGLit = 1 // Inverted constant
+)
+
+// Not returns the inverted edge
+func (l AIGLit) Not() AIGLit {
+	return l ^ 1
+}
+
+// node returns the index of the node the edge points to
+func (l AIGLit) node() int {
+	return int(l >> 1)
+}
+
+// AIGGate is an AND gate whose output is node Node
+type AIGGate struct {
+	Node        int
+	Left, Right AIGLit
+}
+
+// AIG is an And-Inverter Graph: inputs and AND gates connected by possibly inverted edges
+type AIG struct {
+	NumNodes int       // Number of nodes including the constant node 0
+	Inputs   []int     // Node indices of the inputs, in creation order
+	Gates    []AIGGate // AND gates in creation (topological) order
+	Outputs  []AIGLit  // Edges marked as circuit outputs
+}
+
+// NewAIG returns an empty AIG containing only the constant node
+func NewAIG() *AIG {
+	return &AIG{NumNodes: 1}
+}
+
+// Input adds a new input and returns its edge
+func (a *AIG) Input() AIGLit {
+	a.Inputs = append(a.Inputs, a.NumNodes)
+	a.NumNodes++
+	return AIGLit(2 * (a.NumNodes - 1))
+}
+
+// And adds an AND gate over x and y and returns its output edge, folding constants and trivial cases
+func (a *AIG) And(x, y AIGLit) AIGLit {
+	switch {
+	case x == AIGFalse || y == AIGFalse || x == y.Not():
+		return AIGFalse
+	case x == AIGTrue || x == y:
+		return y
+	case y == AIGTrue:
+		return x
+	}
+	a.Gates = append(a.Gates, AIGGate{Node: a.NumNodes, Left: x, Right: y})
+	a.NumNodes++
+	return AIGLit(2 * (a.NumNodes - 1))
+}
+
+// Or returns an edge for x | y built from AND gates
+func (a *AIG) Or(x, y AIGLit) AIGLit {
+	return a.And(x.Not(), y.Not()).Not()
+}
+
+// Xor returns an edge for x ^ y built from AND gates
+func (a *AIG) Xor(x, y AIGLit) AIGLit {
+	return a.Or(a.And(x, y.Not()), a.And(x.Not(), y))
+}
+
+// AddOutput marks an edge as a circuit output
+func (a *AIG) AddOutput(x AIGLit) {
+	a.Outputs = append(a.Outputs, x)
+}
+
+// aigLiteral returns the CNF literal for an edge, where node i is variable i+1
+func aigLiteral(l AIGLit) int {
+	if l&1 == 1 {
+		return -(l.node() + 1)
+	}
+	return l.node() + 1
+}
+
+// AIGToCNF encodes every gate with the standard three clauses and asserts that all outputs are true.
+// Node i becomes variable i+1, so variable 1 is the constant false. It returns the clauses and the number of variables.
+func AIGToCNF(aig AIG) (CNF, int) {
+	cnf := CNF{Clause{-1}} // The constant node is false
+	for _, gate := range aig.Gates {
+		out := gate.Node + 1
+		x, y := aigLiteral(gate.Left), aigLiteral(gate.Right)
+		cnf = append(cnf, Clause{-out, x}, Clause{-out, y}, Clause{out, -x, -y})
+	}
+	for _, output := range aig.Outputs {
+		cnf = append(cnf, Clause{aigLiteral(output)})
+	}
+	return cnf, aig.NumNodes
+}
+
+// embed copies the gates of src into the AIG with its inputs driven by the given edges
+// and returns the edges corresponding to the outputs of src
+func (a *AIG) embed(src AIG, inputs []AIGLit) []AIGLit {
+	mapping := make([]AIGLit, src.NumNodes) // Node 0 maps to AIGFalse
+	for i, node := range src.Inputs {
+		mapping[node] = inputs[i]
+	}
+	edge := func(l AIGLit) AIGLit {
+		return mapping[l.node()] ^ l&1
+	}
+	for _, gate := range src.Gates {
+		mapping[gate.Node] = a.And(edge(gate.Left), edge(gate.Right))
+	}
+	outputs := make([]AIGLit, len(src.Outputs))
+	for i, output := range src.Outputs {
+		outputs[i] = edge(output)
+	}
+	return outputs
+}
+
+// EquivalentCircuits checks whether two circuits compute the same outputs for every input by
+// proving their miter (the OR of the XORs of corresponding outputs) unsatisfiable.
+// Inputs and outputs are paired by position. When the circuits differ it returns the
+// distinguishing input values (0 or 1, in input order); circuits with different numbers
+// of inputs or outputs are reported as not equivalent without a witness.
+func EquivalentCircuits(a, b AIG) (bool, []int) {
+	if len(a.Inputs) != len(b.Inputs) || len(a.Outputs) != len(b.Outputs) {
+		return false, nil
+	}
+	miter := NewAIG()
+	inputs := make([]AIGLit, len(a.Inputs))
+	for i := range inputs {
+		inputs[i] = miter.Input()
+	}
+	outA, outB := miter.embed(a, inputs), miter.embed(b, inputs)
+	differ := AIGFalse
+	for i := range outA {
+		differ = miter.Or(differ, miter.Xor(outA[i], outB[i]))
+	}
+	miter.AddOutput(differ)
+
+	cnf, _ := AIGToCNF(*miter)
+	assignment := make(map[int]bool)
+	if !DPLL(cnf, assignment) {
+		return true, nil
+	}
+	assignment = CompleteAssignment(cnf, assignment)
+	witness := make([]int, len(inputs))
+	for i, input := range inputs {
+		if assignment[aigLiteral(input)] {
+			witness[i] = 1
+		}
+	}
+	return false, witness
+}
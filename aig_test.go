@@ -0,0 +1,44 @@
+package main
+
+import "testing"
+
+func TestAIGToCNF(t *testing.T) {
+	aig := NewAIG()
+	x, y := aig.Input(), aig.Input()
+	aig.AddOutput(aig.Xor(x, y))
+	aig.AddOutput(x)
+	cnf, numVars := AIGToCNF(*aig)
+	assignment := map[int]bool{}
+	if !DPLL(cnf, assignment) || numVars != aig.NumNodes {
+		t.Fatalf("x XOR y with x should be satisfiable, got %v", cnf)
+	}
+	if !assignment[aigLiteral(x)] || assignment[aigLiteral(y)] {
+		t.Fatalf("got %v, want x true and y false", assignment)
+	}
+	aig.AddOutput(y)
+	if cnf, _ := AIGToCNF(*aig); DPLL(cnf, map[int]bool{}) {
+		t.Fatal("x XOR y with x and y is unsatisfiable")
+	}
+}
+
+func TestEquivalentCircuits(t *testing.T) {
+	xor := NewAIG()
+	x, y := xor.Input(), xor.Input()
+	xor.AddOutput(xor.Xor(x, y))
+
+	// (p OR q) AND NOT (p AND q) is XOR built differently
+	rebuilt := NewAIG()
+	p, q := rebuilt.Input(), rebuilt.Input()
+	rebuilt.AddOutput(rebuilt.And(rebuilt.Or(p, q), rebuilt.And(p, q).Not()))
+	if equivalent, witness := EquivalentCircuits(*xor, *rebuilt); !equivalent {
+		t.Fatalf("the circuits differ on %v", witness)
+	}
+
+	or := NewAIG()
+	p, q = or.Input(), or.Input()
+	or.AddOutput(or.Or(p, q))
+	equivalent, witness := EquivalentCircuits(*xor, *or)
+	if equivalent || len(witness) != 2 || witness[0] != 1 || witness[1] != 1 {
+		t.Fatalf("got %v, %v, want the witness 1 1", equivalent, witness)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import "fmt"
+
+// Encoding selects how AtMostOne encodes its constraint
+type Encoding int
+
+const (
+	EncodingPairwise  Encoding = iota // One binary clause per pair, no auxiliary variables: O(n²) clauses
+	EncodingCommander                 // Groups of three with a commander variable each, applied recursively: O(n) clauses
+	EncodingBitwise                   // Each true literal forces its index in binary on log n bits: O(n log n) clauses
+)
+
+// commanderGroupSize is the number of literals sharing a commander variable
+const commanderGroupSize = 3
+
+// AtMostOne encodes that at most one of the literals is true using the given strategy.
+// Pairwise suits a handful of literals; the others scale to large n at the cost of auxiliary
+// variables, which are allocated by incrementing *nextVar.
+func AtMostOne(literals []int, strategy Encoding, nextVar *int) CNF {
+	switch strategy {
+	case EncodingCommander:
+		return atMostOneCommander(literals, nextVar)
+	case EncodingBitwise:
+		return atMostOneBitwise(literals, nextVar)
+	}
+	return atMostOnePairwise(literals)
+}
+
+// atMostOnePairwise forbids every pair of literals from being true together
+func atMostOnePairwise(literals []int) CNF {
+	cnf := CNF{}
+	for i := range literals {
+		for j := i + 1; j < len(literals); j++ {
+			cnf = append(cnf, Clause{-literals[i], -literals[j]})
+		}
+	}
+	return cnf
+}
+
+// atMostOneCommander splits the literals into groups, makes each group's commander variable
+// equivalent to some literal of the group being true, and requires at most one commander recursively
+func atMostOneCommander(literals []int, nextVar *int) CNF {
+	if len(literals) <= 2*commanderGroupSize {
+		return atMostOnePairwise(literals)
+	}
+	cnf := CNF{}
+	commanders := []int{}
+	for start := 0; start < len(literals); start += commanderGroupSize {
+		end := start + commanderGroupSize
+		if end > len(literals) {
+			end = len(literals)
+		}
+		group := literals[start:end]
+		*nextVar++
+		commander := *nextVar
+		commanders = append(commanders, commander)
+		cnf = append(cnf, atMostOnePairwise(group)...)
+		atLeastOne := Clause{-commander}
+		for _, literal := range group {
+			cnf = append(cnf, Clause{-literal, commander})
+			atLeastOne = append(atLeastOne, literal)
+		}
+		cnf = append(cnf, atLeastOne)
+	}
+	return append(cnf, atMostOneCommander(commanders, nextVar)...)
+}
+
+// atMostOneBitwise gives literal i the binary code i on fresh bit variables, so two true literals
+// would force some bit both ways
+func atMostOneBitwise(literals []int, nextVar *int) CNF {
+	if len(literals) <= 1 {
+		return CNF{}
+	}
+	bits := []int{}
+	for size := 1; size < len(literals); size *= 2 {
+		*nextVar++
+		bits = append(bits, *nextVar)
+	}
+	cnf := CNF{}
+	for i, literal := range literals {
+		for j, bit := range bits {
+			if i>>j&1 == 1 {
+				cnf = append(cnf, Clause{-literal, bit})
+			} else {
+				cnf = append(cnf, Clause{-literal, -bit})
+			}
+		}
+	}
+	return cnf
+}
+
+// ExactlyOne encodes that exactly one of the literals is true: one clause requires at least one,
+// and the commander encoding, which is pairwise for a handful of literals, forbids two. Auxiliary
+// variables are allocated by incrementing *nextVar.
+func ExactlyOne(literals []int, nextVar *int) CNF {
+	return append(CNF{append(Clause{}, literals...)}, AtMostOne(literals, EncodingCommander, nextVar)...)
+}
+
+// DecodeOneHot returns the index in literals of the only literal the model makes true, as
+// constrained by ExactlyOne. It fails when none or several of them are true.
+func DecodeOneHot(model map[int]bool, literals []int) (int, error) {
+	index := -1
+	for i, literal := range literals {
+		if model[abs(literal)] != (literal > 0) {
+			continue
+		}
+		if index >= 0 {
+			return -1, fmt.Errorf("one-hot: literals %d and %d are both true", literals[index], literal)
+		}
+		index = i
+	}
+	if index < 0 {
+		return -1, fmt.Errorf("one-hot: none of %d literals is true", len(literals))
+	}
+	return index, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import "testing"
+
+func TestAtMostOne(t *testing.T) {
+	for _, strategy := range []Encoding{EncodingPairwise, EncodingCommander, EncodingBitwise} {
+		for n := 1; n <= 8; n++ {
+			literals := []int{}
+			for variable := 1; variable <= n; variable++ {
+				if variable%3 == 0 {
+					literals = append(literals, -variable) // Negated literals are constrained too
+				} else {
+					literals = append(literals, variable)
+				}
+			}
+			nextVar := n
+			cnf := AtMostOne(literals, strategy, &nextVar)
+			for mask := 0; mask < 1<<n; mask++ {
+				fixed := append(CNF{}, cnf...)
+				trueLiterals := 0
+				for i, literal := range literals {
+					if mask&(1<<i) != 0 {
+						fixed = append(fixed, Clause{literal})
+						trueLiterals++
+					} else {
+						fixed = append(fixed, Clause{-literal})
+					}
+				}
+				if got := DPLL(fixed, map[int]bool{}); got != (trueLiterals <= 1) {
+					t.Fatalf("encoding %d, %d literals, %d true: got %v", strategy, n, trueLiterals, got)
+				}
+			}
+		}
+	}
+}
+
+func TestExactlyOne(t *testing.T) {
+	for n := 2; n <= 12; n++ {
+		literals := []int{}
+		for variable := 1; variable <= n; variable++ {
+			literals = append(literals, variable)
+		}
+		nextVar := n
+		cnf := append(ExactlyOne(literals, &nextVar), Clause{-1})
+		satisfiable, model := Solve(cnf)
+		if !satisfiable {
+			t.Fatalf("%d literals: got UNSAT with the first one false", n)
+		}
+		if index, err := DecodeOneHot(model, literals); err != nil || index == 0 {
+			t.Fatalf("%d literals: decoded %d, %v", n, index, err)
+		}
+		if n <= 6 {
+			if count := CountModels(ExactlyOne(literals, new(int)), n); count.Int64() != int64(n) {
+				t.Fatalf("%d literals: got %v models, want %d", n, count, n)
+			}
+		}
+	}
+	for _, c := range []struct {
+		model    map[int]bool
+		literals []int
+	}{
+		{map[int]bool{1: true, 2: true}, []int{1, 2}},
+		{map[int]bool{}, []int{1, 2}},
+		{map[int]bool{1: true}, []int{1, -2}}, // The negated literal is true as well
+	} {
+		if index, err := DecodeOneHot(c.model, c.literals); err == nil {
+			t.Errorf("%v over %v: decoded %d, want an error", c.model, c.literals, index)
+		}
+	}
+}
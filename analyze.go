@@ -0,0 +1,80 @@
+package main
+
+// ConflictAnalyzer derives a learned clause from a conflict for a Solver set up with
+// WithConflictAnalyzer. Analyze gets the falsified clause, the trail of assigned literals in
+// order, the clause that implied each literal of the trail (nil for decisions and assumptions)
+// and the decision level of each variable. It returns a clause implied by the solver's clauses
+// whose literals are all false, exactly one of them at the highest level, and the level to jump
+// back to, below that one; the solver then propagates the learned clause. Returning the empty
+// clause reports that the formula is unsatisfiable.
+type ConflictAnalyzer interface {
+	Analyze(conflict Clause, trail []int, reason func(literal int) Clause, level func(variable int) int) (learned Clause, backjump int)
+}
+
+// FirstUIP is the standard conflict analysis: it resolves the conflict with the reasons of the
+// literals of the latest level, newest first, until a single literal of that level is left,
+// then drops every literal implied by the others through its reason
+type FirstUIP struct{}
+
+func (FirstUIP) Analyze(conflict Clause, trail []int, reason func(literal int) Clause, level func(variable int) int) (Clause, int) {
+	current := 0
+	for _, literal := range conflict {
+		if l := level(abs(literal)); l > current {
+			current = l
+		}
+	}
+	if current == 0 {
+		return Clause{}, 0
+	}
+	seen := make(map[int]bool)
+	learned := Clause{0} // Room for the asserting literal
+	pending := 0         // Literals of the current level still to resolve
+	clause, uip := conflict, 0
+	for i := len(trail) - 1; ; i-- {
+		for _, literal := range clause {
+			variable := abs(literal)
+			if variable == abs(uip) || seen[variable] || level(variable) == 0 {
+				continue
+			}
+			seen[variable] = true
+			if level(variable) == current {
+				pending++
+			} else {
+				learned = append(learned, literal)
+			}
+		}
+		for !seen[abs(trail[i])] {
+			i--
+		}
+		uip = trail[i]
+		pending--
+		if pending == 0 {
+			break
+		}
+		clause = reason(uip)
+	}
+	learned[0] = -uip
+
+	// Minimization: a literal is redundant when the other literals of its reason are all learned.
+	// They come from lower levels, where seen marks exactly the learned variables.
+	minimized := Clause{learned[0]}
+	for _, literal := range learned[1:] {
+		redundant := reason(-literal) != nil
+		for _, other := range reason(-literal) {
+			if abs(other) != abs(literal) && !seen[abs(other)] && level(abs(other)) != 0 {
+				redundant = false
+				break
+			}
+		}
+		if !redundant {
+			minimized = append(minimized, literal)
+		}
+	}
+	backjump := 0
+	for _, literal := range minimized[1:] {
+		if l := level(abs(literal)); l > backjump {
+			backjump = l
+		}
+	}
+	return minimized, backjump
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"math/rand"
+	"sort"
+)
+
+// xorClauses encodes x1 XOR x2 XOR ... = parity as a chain of two-input XOR definitions,
+// allocating the intermediate variables by incrementing *nextVar
+func xorClauses(vars []int, parity bool, nextVar *int) CNF {
+	if len(vars) == 0 {
+		if parity {
+			return CNF{Clause{}} // 0 = 1 is false
+		}
+		return CNF{}
+	}
+	cnf := CNF{}
+	acc := vars[0]
+	for _, x := range vars[1:] {
+		*nextVar++
+		g := *nextVar // g <-> acc XOR x
+		cnf = append(cnf, Clause{-g, acc, x}, Clause{-g, -acc, -x}, Clause{g, -acc, x}, Clause{g, acc, -x})
+		acc = g
+	}
+	if parity {
+		return append(cnf, Clause{acc})
+	}
+	return append(cnf, Clause{-acc})
+}
+
+// boundedCount counts the models of the CNF over variables 1..numVars, stopping at limit
+func boundedCount(cnf CNF, numVars, limit int) int {
+	blocked := append(CNF{}, cnf...)
+	for count := 0; count < limit; count++ {
+		assignment := make(map[int]bool)
+		if !DPLL(blocked, assignment) {
+			return count
+		}
+		block := Clause{}
+		for variable := 1; variable <= numVars; variable++ {
+			if value, exists := assignment[variable]; exists && !value {
+				block = append(block, variable)
+			} else { // Unconstrained variables count as true
+				block = append(block, -variable)
+			}
+		}
+		blocked = append(blocked, block)
+	}
+	return limit
+}
+
+// ApproxCount estimates the number of models over variables 1..numVars in the style of ApproxMC:
+// random XOR constraints split the models into small cells whose size, scaled by the number of
+// cells, estimates the count. The result is within a factor (1+epsilon) of the true count with
+// probability at least 1-delta.
+func ApproxCount(cnf CNF, numVars int, epsilon, delta float64, seed int64) (*big.Int, error) {
+	if epsilon <= 0 {
+		return nil, errors.New("approxcount: epsilon must be positive")
+	}
+	if delta <= 0 || delta >= 1 {
+		return nil, errors.New("approxcount: delta must be between 0 and 1")
+	}
+	if v := maxVariable(cnf, nil); v > numVars {
+		numVars = v
+	}
+	threshold := int(1 + 9.84*(1+epsilon/(1+epsilon))*(1+1/epsilon)*(1+1/epsilon))
+	count := boundedCount(cnf, numVars, threshold+1)
+	if count <= threshold { // Few enough models to count exactly
+		return big.NewInt(int64(count)), nil
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	iterations := int(math.Ceil(17 * math.Log2(3/delta)))
+	estimates := []*big.Int{}
+	for i := 0; i < iterations; i++ {
+		hashed := append(CNF{}, cnf...)
+		nextVar := numVars
+		for m := 1; m <= numVars; m++ {
+			vars := []int{}
+			for variable := 1; variable <= numVars; variable++ {
+				if rng.Intn(2) == 1 {
+					vars = append(vars, variable)
+				}
+			}
+			hashed = append(hashed, xorClauses(vars, rng.Intn(2) == 1, &nextVar)...)
+			cell := boundedCount(hashed, numVars, threshold+1)
+			if cell <= threshold {
+				estimates = append(estimates, new(big.Int).Lsh(big.NewInt(int64(cell)), uint(m)))
+				break
+			}
+		}
+	}
+	if len(estimates) == 0 {
+		return nil, errors.New("approxcount: no cell of the right size was found")
+	}
+	sort.Slice(estimates, func(i, j int) bool {
+		return estimates[i].Cmp(estimates[j]) < 0
+	})
+	return estimates[len(estimates)/2], nil
+}
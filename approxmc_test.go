@@ -0,0 +1,38 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestApproxCount(t *testing.T) {
+	cnf := CNF{{1, 2}, {-3, 4}} // 3 * 3 * 2^4 = 144 models over 8 variables
+	const exact, epsilon = 144, 2.0
+	within := 0
+	seeds := 5
+	for seed := int64(1); seed <= int64(seeds); seed++ {
+		count, err := ApproxCount(cnf, 8, epsilon, 0.5, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c := float64(count.Int64()); c >= exact/(1+epsilon) && c <= exact*(1+epsilon) {
+			within++
+		}
+	}
+	if within < seeds-1 {
+		t.Fatalf("only %d of %d estimates were within a factor %v of %d", within, seeds, 1+epsilon, exact)
+	}
+}
+
+func TestApproxCountSmall(t *testing.T) {
+	count, err := ApproxCount(CNF{{1, 2}, {-1, -2}}, 3, 0.8, 0.2, 1)
+	if err != nil || count.Cmp(big.NewInt(4)) != 0 {
+		t.Fatalf("a formula with few models is counted exactly: got %v, %v, want 4", count, err)
+	}
+	if _, err := ApproxCount(CNF{{1}}, 1, 0, 0.2, 1); err == nil {
+		t.Fatal("epsilon 0 was accepted")
+	}
+	if _, err := ApproxCount(CNF{{1}}, 1, 0.8, 1, 1); err == nil {
+		t.Fatal("delta 1 was accepted")
+	}
+}
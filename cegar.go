@@ -0,0 +1,88 @@
+package main
+
+import "strings"
+
+// IsTautologyCEGAR decides whether the propositional formula is a tautology by refining an
+// abstraction of its negation instead of encoding it whole. Operator subformulas start as free
+// variables; each model of the abstraction is checked against the real formula, and when it is
+// spurious the subformulas whose abstract value disagrees with their real value get their Tseitin
+// definitions. Structured formulas are often decided before most of the encoding is built.
+func IsTautologyCEGAR(expr string) (bool, error) {
+	if strings.TrimSpace(expr) == "" {
+		return false, ErrEmptyInput
+	}
+	if err := CheckParentheses(expr); err != nil {
+		return false, err
+	}
+	root, err := parseExpression(expr)
+	if err != nil {
+		return false, err
+	}
+	names := variableNames(root)
+	a := abstraction{vars: make(map[string]int), gates: make(map[*Node]int), expanded: make(map[*Node]bool)}
+	cnf := CNF{{-a.literal(root)}}
+	for {
+		assignment := make(map[int]bool)
+		if !DPLL(cnf, assignment) {
+			return true, nil // The negation is unsatisfiable
+		}
+		assignment = CompleteAssignment(cnf, assignment)
+		values := make(map[string]bool)
+		for _, name := range names { // Variables the abstraction does not mention yet are false
+			values[name] = assignment[a.vars[name]]
+		}
+		if holds, _ := Eval(root, values); !holds {
+			return false, nil // A real counterexample
+		}
+		cnf = append(cnf, a.refine(root, assignment, values)...)
+	}
+}
+
+// abstraction maps the nodes of a formula to variables and tracks which operator nodes are defined
+type abstraction struct {
+	vars     map[string]int // Formula variables
+	gates    map[*Node]int  // Operator nodes
+	next     int
+	expanded map[*Node]bool // Operator nodes whose definition has been added
+}
+
+// literal returns the literal standing for the node, allocating a variable if needed
+func (a *abstraction) literal(node *Node) int {
+	switch {
+	case node.Value == "!":
+		return -a.literal(node.Left)
+	case node.Value == "TRUE":
+		return LiteralTrue
+	case node.Value == "FALSE":
+		return LiteralFalse
+	case node.Left == nil && node.Right == nil:
+		if _, exists := a.vars[node.Value]; !exists {
+			a.next++
+			a.vars[node.Value] = a.next
+		}
+		return a.vars[node.Value]
+	}
+	if _, exists := a.gates[node]; !exists {
+		a.next++
+		a.gates[node] = a.next
+	}
+	return a.gates[node]
+}
+
+// refine returns the definitions of the undefined operator nodes reachable through defined ones
+// whose value in the abstract model differs from their value under the formula variables
+func (a *abstraction) refine(node *Node, assignment map[int]bool, values map[string]bool) CNF {
+	switch {
+	case node.Value == "!":
+		return a.refine(node.Left, assignment, values)
+	case node.Left == nil && node.Right == nil:
+		return CNF{}
+	case a.expanded[node]:
+		return append(a.refine(node.Left, assignment, values), a.refine(node.Right, assignment, values)...)
+	}
+	if holds, _ := Eval(node, values); assignment[a.gates[node]] == holds {
+		return CNF{}
+	}
+	a.expanded[node] = true
+	return gateClauses(node.Value, a.gates[node], a.literal(node.Left), a.literal(node.Right))
+}
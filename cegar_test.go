@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestIsTautologyCEGAR(t *testing.T) {
+	// Distributing this disjunction of conjunctions would give 2^20 clauses
+	terms := []string{}
+	for i := 1; i <= 20; i++ {
+		terms = append(terms, fmt.Sprintf("(A%d & B%d)", i, i))
+	}
+	dnf := strings.Join(terms, " | ")
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"(" + dnf + ") | !(" + dnf + ")", true},
+		{"(" + dnf + ") -> (" + dnf + ")", true},
+		{dnf, false},
+		{"A | !A", true},
+		{"A -> B", false},
+		{"1", true},
+	}
+	for _, test := range tests {
+		got, err := IsTautologyCEGAR(test.expr)
+		if err != nil || got != test.want {
+			t.Errorf("IsTautologyCEGAR(%.40q): got %v, %v, want %v", test.expr, got, err, test.want)
+		}
+	}
+}
+
+func TestIsTautologyCEGARMalformed(t *testing.T) {
+	for _, expr := range []string{"A &", "!", "()", "A | (B"} {
+		holds, err := IsTautologyCEGAR(expr)
+		var parseErr *ParseError
+		if holds || !errors.As(err, &parseErr) {
+			t.Errorf("IsTautologyCEGAR(%q): got %v, %v, want a ParseError", expr, holds, err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CompareHeuristics solves every instance with every decider and returns, for each decider, the
+// statistics summed over the instances. Instances not decided within timeout are counted in
+// Unknown, with the work spent on them included in the totals. Every solver is built with opts
+// followed by WithDecider. Deciders are keyed by their String method if they have one, and
+// otherwise by their type and fields, such as "MOMDecider{K:4}". A clause the solvers reject
+// stops the comparison with its error.
+func CompareHeuristics(instances []CNF, deciders []Decider, timeout time.Duration, opts ...Option) (map[string]Stats, error) {
+	results := make(map[string]Stats, len(deciders))
+	for _, d := range deciders {
+		total := Stats{}
+		for i, cnf := range instances {
+			s := NewSolver(append(append([]Option{}, opts...), WithDecider(d))...)
+			for _, clause := range cnf {
+				if err := s.AddClause(clause); err != nil {
+					return nil, fmt.Errorf("instance %d: %w", i, err)
+				}
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			_, err := s.SolveContext(ctx)
+			cancel()
+			stats := s.Stats()
+			if err != nil {
+				total.Unknown++
+			}
+			total.Decisions += stats.Decisions
+			total.Conflicts += stats.Conflicts
+			total.Propagations += stats.Propagations
+			total.PropagationTime += stats.PropagationTime
+			total.ConflictTime += stats.ConflictTime
+			total.DecisionTime += stats.DecisionTime
+			total.SolveTime += stats.SolveTime
+		}
+		results[deciderName(d)] = total
+	}
+	return results, nil
+}
+
+// deciderName names a decider for CompareHeuristics
+func deciderName(d Decider) string {
+	if stringer, ok := d.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T%+v", d, d), "main.")
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCompareHeuristics(t *testing.T) {
+	instances := []CNF{
+		{{1, 2}, {-1, 2}, {1, -2}, {-1, -2}},
+		{{1, 2, 3}, {-1, -2}, {-3, 1}},
+	}
+	results, err := CompareHeuristics(instances, []Decider{MOMDecider{}, shortestClauseDecider{}, MOMDecider{K: 2}}, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"MOMDecider{K:0}", "shortestClauseDecider{}", "MOMDecider{K:2}"} {
+		stats, exists := results[name]
+		if !exists {
+			t.Fatalf("no entry for %s in %v", name, results)
+		}
+		if stats.Unknown != 0 || stats.Decisions == 0 || stats.Conflicts == 0 {
+			t.Errorf("%s: %+v", name, stats)
+		}
+	}
+	if len(results) != 3 {
+		t.Fatalf("got %d entries, want one per decider", len(results))
+	}
+
+	if results, err := CompareHeuristics(instances, []Decider{MOMDecider{}}, 0); err != nil || results["MOMDecider{K:0}"].Unknown != 2 {
+		t.Fatalf("every instance times out at once, got %+v, %v", results, err)
+	}
+
+	var validationErr *ValidationError
+	rejected := append(instances, CNF{{1, 1}})
+	if _, err := CompareHeuristics(rejected, []Decider{MOMDecider{}}, time.Second, WithClauseCheck(ClauseReject)); !errors.As(err, &validationErr) {
+		t.Fatalf("got %v, want the ValidationError of the rejected clause", err)
+	}
+}
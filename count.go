@@ -0,0 +1,95 @@
+package main
+
+import (
+	"math/big"
+	"sync"
+)
+
+// CountModels returns the exact number of assignments to variables 1..numVars (or to every
+// variable of the CNF, if there are more) that satisfy the CNF
+func CountModels(cnf CNF, numVars int) *big.Int {
+	cnf = resolveConstants(cnf)
+	if v := maxVariable(cnf, nil); v > numVars {
+		numVars = v
+	}
+	return countModels(cnf, numVars)
+}
+
+// countModels counts the models of the CNF over free unassigned variables, which include
+// every variable of the CNF, by branching on the first variable of the first clause
+func countModels(cnf CNF, free int) *big.Int {
+	if len(cnf) == 0 {
+		return new(big.Int).Lsh(big.NewInt(1), uint(free))
+	}
+	for _, clause := range cnf {
+		if len(clause) == 0 {
+			return big.NewInt(0)
+		}
+		if len(clause) == 1 { // A unit clause leaves a single branch
+			return countModels(assign(cnf, abs(clause[0]), clause[0] > 0), free-1)
+		}
+	}
+	variable := abs(cnf[0][0])
+	count := countModels(assign(cnf, variable, true), free-1)
+	return count.Add(count, countModels(assign(cnf, variable, false), free-1))
+}
+
+// countJob is a subproblem of CountModelsParallel
+type countJob struct {
+	cnf  CNF
+	free int
+}
+
+// CountModelsParallel is CountModels that splits the search into subproblems by branching on the
+// first few variables and counts them on a pool of workers goroutines. Subproblems share no
+// mutable state: every simplification builds new clauses.
+func CountModelsParallel(cnf CNF, numVars, workers int) *big.Int {
+	cnf = resolveConstants(cnf)
+	if v := maxVariable(cnf, nil); v > numVars {
+		numVars = v
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	depth := 2 // Several subproblems per worker even out their sizes
+	for n := 1; n < workers; n *= 2 {
+		depth++
+	}
+
+	total := big.NewInt(0)
+	jobs := []countJob{{cnf, numVars}}
+	for level := 0; level < depth; level++ {
+		next := []countJob{}
+		for _, job := range jobs {
+			if len(job.cnf) == 0 || len(job.cnf[0]) == 0 { // Leave trivial subproblems to a worker
+				next = append(next, job)
+				continue
+			}
+			variable := abs(job.cnf[0][0])
+			next = append(next, countJob{assign(job.cnf, variable, true), job.free - 1}, countJob{assign(job.cnf, variable, false), job.free - 1})
+		}
+		jobs = next
+	}
+
+	queue := make(chan countJob)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				count := countModels(job.cnf, job.free)
+				mu.Lock()
+				total.Add(total, count)
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, job := range jobs {
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+	return total
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestCountModelsParallel(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	for round := 0; round < 100; round++ {
+		n := 1 + rng.Intn(10)
+		cnf := CNF{}
+		for i := rng.Intn(3 * n); i >= 0; i-- {
+			clause := Clause{}
+			for k := rng.Intn(3); k >= 0; k-- {
+				literal := rng.Intn(n) + 1
+				if rng.Intn(2) == 0 {
+					literal = -literal
+				}
+				clause = append(clause, literal)
+			}
+			cnf = append(cnf, clause)
+		}
+		brute := 0 // Variable n+1 is unconstrained and doubles the count
+		for mask := 0; mask < 1<<(n+1); mask++ {
+			assignment := make(map[int]bool)
+			for variable := 1; variable <= n+1; variable++ {
+				assignment[variable] = mask>>(variable-1)&1 == 1
+			}
+			if len(UnsatisfiedClauses(cnf, assignment)) == 0 {
+				brute++
+			}
+		}
+		sequential := CountModels(cnf, n+1)
+		parallel := CountModelsParallel(cnf, n+1, 1+rng.Intn(6))
+		if sequential.Cmp(big.NewInt(int64(brute))) != 0 || parallel.Cmp(sequential) != 0 {
+			t.Fatalf("%v: CountModels %v, CountModelsParallel %v, want %d", cnf, sequential, parallel, brute)
+		}
+	}
+	if got := CountModelsParallel(random3SAT(30, 60, 1), 30, 4); got.Cmp(CountModels(random3SAT(30, 60, 1), 30)) != 0 {
+		t.Fatalf("got %v on a larger instance", got)
+	}
+}
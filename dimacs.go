@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ParseDIMACS reads a formula in DIMACS CNF format and returns its clauses and the
+// number of variables declared by the "p cnf" header
+func ParseDIMACS(r io.Reader) (CNF, int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	cnf := CNF{}
+	numVars := 0
+	clause := Clause{}
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] == "c" {
+			continue
+		}
+		if fields[0] == "%" { // End marker used by SATLIB benchmarks
+			break
+		}
+		if fields[0] == "p" {
+			if len(fields) != 4 || fields[1] != "cnf" {
+				return nil, 0, fmt.Errorf("dimacs: line %d: malformed header", line)
+			}
+			n, err := strconv.Atoi(fields[2])
+			if err != nil || n < 0 {
+				return nil, 0, fmt.Errorf("dimacs: line %d: bad variable count %q", line, fields[2])
+			}
+			numVars = n
+			continue
+		}
+		for _, field := range fields {
+			literal, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, 0, fmt.Errorf("dimacs: line %d: bad literal %q", line, field)
+			}
+			if literal == 0 { // 0 terminates a clause, which may span several lines
+				cnf = append(cnf, clause)
+				clause = Clause{}
+				continue
+			}
+			clause = append(clause, literal)
+			if abs(literal) > numVars {
+				numVars = abs(literal)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+	if len(clause) > 0 { // Tolerate a missing final 0
+		cnf = append(cnf, clause)
+	}
+	return cnf, numVars, nil
+}
+
+// ParseWCNF reads a weighted MaxSAT formula in DIMACS WCNF format, where every clause starts with
+// its weight and the "p wcnf <vars> <clauses> <top>" header gives the weight top that marks hard
+// clauses. The header may leave out top, as in formulas without hard clauses, and the newer format
+// without a header, where hard clauses start with "h", is accepted too; top is then 0. Soft clauses
+// of weight 0 cost nothing when falsified and are skipped.
+func ParseWCNF(r io.Reader) (hard CNF, soft []WeightedClause, top int, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	hard = CNF{}
+	soft = []WeightedClause{}
+	const hardWeight = -1
+	weight, started := 0, false // Weight of the clause being read, once it is given
+	clause := Clause{}
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] == "c" {
+			continue
+		}
+		if fields[0] == "p" {
+			if len(fields) != 4 && len(fields) != 5 || fields[1] != "wcnf" {
+				return nil, nil, 0, fmt.Errorf("wcnf: line %d: malformed header", line)
+			}
+			if len(fields) == 5 {
+				t, err := strconv.Atoi(fields[4])
+				if err != nil || t <= 0 {
+					return nil, nil, 0, fmt.Errorf("wcnf: line %d: bad top weight %q", line, fields[4])
+				}
+				top = t
+			}
+			continue
+		}
+		for _, field := range fields {
+			if !started {
+				started = true
+				if field == "h" {
+					weight = hardWeight
+					continue
+				}
+				w, err := strconv.Atoi(field)
+				if err != nil || w < 0 {
+					return nil, nil, 0, fmt.Errorf("wcnf: line %d: bad weight %q", line, field)
+				}
+				if top > 0 && w >= top {
+					w = hardWeight
+				}
+				weight = w
+				continue
+			}
+			literal, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, nil, 0, fmt.Errorf("wcnf: line %d: bad literal %q", line, field)
+			}
+			if literal != 0 {
+				clause = append(clause, literal)
+				continue
+			}
+			switch {
+			case weight == hardWeight:
+				hard = append(hard, clause)
+			case weight > 0:
+				soft = append(soft, WeightedClause{Weight: weight, Clause: clause})
+			}
+			started, clause = false, Clause{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, 0, err
+	}
+	if started {
+		return nil, nil, 0, fmt.Errorf("wcnf: last clause is not terminated by 0")
+	}
+	return hard, soft, top, nil
+}
+
+// ParseICNF reads an incremental formula in iCNF format: DIMACS clauses after a "p inccnf" header,
+// followed by "a" lines, each giving a set of assumption literals ended by 0 to solve the clauses
+// under in turn. Clauses added between assumption sets are not supported.
+func ParseICNF(r io.Reader) (CNF, [][]int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	cnf := CNF{}
+	assumptions := [][]int{}
+	clause := Clause{}
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] == "c" {
+			continue
+		}
+		if fields[0] == "p" {
+			if len(fields) != 2 || fields[1] != "inccnf" {
+				return nil, nil, fmt.Errorf("icnf: line %d: malformed header", line)
+			}
+			continue
+		}
+		if fields[0] == "a" {
+			if len(fields) < 2 || fields[len(fields)-1] != "0" {
+				return nil, nil, fmt.Errorf("icnf: line %d: assumptions are not terminated by 0", line)
+			}
+			set := []int{}
+			for _, field := range fields[1 : len(fields)-1] {
+				literal, err := strconv.Atoi(field)
+				if err != nil || literal == 0 {
+					return nil, nil, fmt.Errorf("icnf: line %d: bad assumption %q", line, field)
+				}
+				set = append(set, literal)
+			}
+			assumptions = append(assumptions, set)
+			continue
+		}
+		if len(assumptions) > 0 {
+			return nil, nil, fmt.Errorf("icnf: line %d: clauses after assumptions are not supported", line)
+		}
+		for _, field := range fields {
+			literal, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, nil, fmt.Errorf("icnf: line %d: bad literal %q", line, field)
+			}
+			if literal == 0 {
+				cnf = append(cnf, clause)
+				clause = Clause{}
+				continue
+			}
+			clause = append(clause, literal)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	if len(clause) > 0 {
+		return nil, nil, fmt.Errorf("icnf: last clause is not terminated by 0")
+	}
+	return cnf, assumptions, nil
+}
+
+// icnfCommand solves the iCNF file named by args (or standard input) under each of its assumption
+// sets in turn, on one incremental Solver, and prints one numbered result per set.
+// It returns 1 on error and 0 otherwise.
+func icnfCommand(args []string, w io.Writer) int {
+	input := io.Reader(os.Stdin)
+	if len(args) > 0 {
+		file, err := os.Open(args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer file.Close()
+		input = file
+	}
+	cnf, assumptions, err := ParseICNF(input)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	s := NewSolver()
+	for _, clause := range cnf {
+		s.AddClause(clause)
+	}
+	for i, set := range assumptions {
+		if s.SolveUnder(set) {
+			fmt.Fprintf(w, "%d: SATISFIABLE\n", i+1)
+		} else {
+			fmt.Fprintf(w, "%d: UNSATISFIABLE\n", i+1)
+		}
+	}
+	return 0
+}
+
+// WriteCompetitionResult writes the result in SAT competition format: an "s" status line and,
+// for a satisfiable formula, "v" lines giving every variable 1..numVars as a literal, ending with 0
+func WriteCompetitionResult(w io.Writer, sat bool, model map[int]bool, numVars int) error {
+	bw := bufio.NewWriter(w)
+	if !sat {
+		fmt.Fprintln(bw, "s UNSATISFIABLE")
+		return bw.Flush()
+	}
+	fmt.Fprintln(bw, "s SATISFIABLE")
+	line := "v"
+	for variable := 1; variable <= numVars; variable++ {
+		literal := variable
+		if !model[variable] {
+			literal = -variable
+		}
+		if len(line) > 70 { // Keep value lines short
+			fmt.Fprintln(bw, line)
+			line = "v"
+		}
+		line += " " + strconv.Itoa(literal)
+	}
+	fmt.Fprintln(bw, line+" 0")
+	return bw.Flush()
+}
+
+// solveCommand solves the DIMACS file named by args (or standard input) and prints the result
+// in competition format. It returns the conventional exit code: 10 for SAT, 20 for UNSAT, 1 on error.
+func solveCommand(args []string, w io.Writer) int {
+	input := io.Reader(os.Stdin)
+	if len(args) > 0 {
+		file, err := os.Open(args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer file.Close()
+		input = file
+	}
+	cnf, numVars, err := ParseDIMACS(input)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	assignment := make(map[int]bool)
+	sat := DPLL(cnf, assignment)
+	if sat {
+		assignment = CompleteAssignment(cnf, assignment)
+	}
+	if err := WriteCompetitionResult(w, sat, assignment, numVars); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if sat {
+		return 10
+	}
+	return 20
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSolveCommandOutput(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		input, want string
+		status      int
+	}{
+		{"c example\np cnf 3 3\n1 -2 0\n-1 0\n3 0\n", "s SATISFIABLE\nv -1 -2 3 0\n", 10},
+		{"p cnf 1 2\n1 0\n-1 0\n", "s UNSATISFIABLE\n", 20},
+	}
+	for i, test := range tests {
+		path := filepath.Join(dir, fmt.Sprintf("formula%d.cnf", i))
+		if err := os.WriteFile(path, []byte(test.input), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		var out bytes.Buffer
+		if status := solveCommand([]string{path}, &out); out.String() != test.want || status != test.status {
+			t.Errorf("solve %q:\n%s(status %d), want\n%s(status %d)", test.input, out.String(), status, test.want, test.status)
+		}
+	}
+}
+
+func TestWriteCompetitionResultLongModel(t *testing.T) {
+	model := make(map[int]bool)
+	for variable := 1; variable <= 60; variable += 2 {
+		model[variable] = true
+	}
+	var out bytes.Buffer
+	if err := WriteCompetitionResult(&out, true, model, 60); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if lines[0] != "s SATISFIABLE" || len(lines) < 3 {
+		t.Fatalf("got %q, want a status line and several value lines", out.String())
+	}
+	literals := []string{}
+	for _, line := range lines[1:] {
+		if !strings.HasPrefix(line, "v ") {
+			t.Fatalf("value line %q does not start with v", line)
+		}
+		literals = append(literals, strings.Fields(line)[1:]...)
+	}
+	if len(literals) != 61 || literals[60] != "0" || literals[0] != "1" || literals[1] != "-2" || literals[59] != "-60" {
+		t.Fatalf("got literals %v, want 1 -2 ... -60 0", literals)
+	}
+}
+
+func TestParseWCNF(t *testing.T) {
+	input := "c weighted\np wcnf 3 5 10\n10 1 -2 0\n3 2 0\n10 -3\n 0\n1 3 1 0\n12 2 3 0\n"
+	hard, soft, top, err := ParseWCNF(strings.NewReader(input))
+	if err != nil || top != 10 {
+		t.Fatalf("got top %d, %v, want 10", top, err)
+	}
+	if want := (CNF{{1, -2}, {-3}, {2, 3}}); !reflect.DeepEqual(hard, want) {
+		t.Errorf("hard: got %v, want %v", hard, want)
+	}
+	if want := []WeightedClause{{3, Clause{2}}, {1, Clause{3, 1}}}; !reflect.DeepEqual(soft, want) {
+		t.Errorf("soft: got %v, want %v", soft, want)
+	}
+
+	hard, soft, top, err = ParseWCNF(strings.NewReader("h 1 2 0\n5 -1 0\n"))
+	if err != nil || top != 0 || !reflect.DeepEqual(hard, CNF{{1, 2}}) || !reflect.DeepEqual(soft, []WeightedClause{{5, Clause{-1}}}) {
+		t.Errorf("headerless format: got %v, %v, %d, %v", hard, soft, top, err)
+	}
+	hard, soft, top, err = ParseWCNF(strings.NewReader("p wcnf 2 3\n2 1 2 0\n0 -1 0\n1 -2 0\n"))
+	if err != nil || top != 0 || len(hard) != 0 || !reflect.DeepEqual(soft, []WeightedClause{{2, Clause{1, 2}}, {1, Clause{-2}}}) {
+		t.Errorf("header without top and a weight-0 clause: got %v, %v, %d, %v", hard, soft, top, err)
+	}
+	for _, input := range []string{"p wcnf 1 1 5\n3 1\n", "p wcnf 1\n", "-1 1 0\n", "p wcnf 1 1 5\n2 x 0\n"} {
+		if _, _, _, err := ParseWCNF(strings.NewReader(input)); err == nil {
+			t.Errorf("ParseWCNF(%q): got no error", input)
+		}
+	}
+}
+
+func TestICNF(t *testing.T) {
+	input := "c example\np inccnf\n1 2 0\n-1 3\n0\n-2 -3 0\na 1 0\na -3 1 0\na 0\n"
+	cnf, assumptions, err := ParseICNF(strings.NewReader(input))
+	if want := (CNF{{1, 2}, {-1, 3}, {-2, -3}}); err != nil || !reflect.DeepEqual(cnf, want) {
+		t.Fatalf("got %v, %v, want %v", cnf, err, want)
+	}
+	if want := [][]int{{1}, {-3, 1}, {}}; !reflect.DeepEqual(assumptions, want) {
+		t.Fatalf("got the assumptions %v, want %v", assumptions, want)
+	}
+	path := filepath.Join(t.TempDir(), "formula.icnf")
+	if err := os.WriteFile(path, []byte(input), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if status := icnfCommand([]string{path}, &out); status != 0 || out.String() != "1: SATISFIABLE\n2: UNSATISFIABLE\n3: SATISFIABLE\n" {
+		t.Fatalf("icnf: got\n%s(status %d)", out.String(), status)
+	}
+	for _, malformed := range []string{"p cnf 1 1\n", "a 1\n", "a 1 0\n1 0\n", "1 2\n", "a x 0\n"} {
+		if _, _, err := ParseICNF(strings.NewReader(malformed)); err == nil {
+			t.Errorf("%q was parsed", malformed)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// GateKind identifies the boolean function a Gate computes
+type GateKind int
+
+const (
+	GateAnd GateKind = iota // Output = AND of inputs
+	GateOr                  // Output = OR of inputs
+	GateXor                 // Output = XOR of inputs
+	GateITE                 // Output = Inputs[0] ? Inputs[1] : Inputs[2]
+)
+
+func (k GateKind) String() string {
+	names := [...]string{"AND", "OR", "XOR", "ITE"}
+	if k < 0 || int(k) >= len(names) {
+		return fmt.Sprintf("GateKind(%d)", int(k))
+	}
+	return names[k]
+}
+
+// Gate is a definition Output = Kind(Inputs) recovered from clauses; Output and Inputs are literals
+type Gate struct {
+	Kind    GateKind
+	Output  int
+	Inputs  []int
+	Clauses []int // Indices of the defining clauses
+}
+
+// clauseKey returns a key identifying a clause by its set of literals
+func clauseKey(literals ...int) string {
+	sorted := append([]int{}, literals...)
+	sort.Ints(sorted)
+	return fmt.Sprint(sorted)
+}
+
+// DetectGates recognizes AND/OR, XOR and ITE definitions encoded by the clauses of the CNF,
+// such as (-o OR a), (-o OR b), (o OR -a OR -b) defining o = a AND b.
+// An XOR over three variables is reported with the largest variable as the output.
+func DetectGates(cnf CNF) []Gate {
+	index := make(map[string]int)
+	for i, clause := range cnf {
+		if _, exists := index[clauseKey(clause...)]; !exists {
+			index[clauseKey(clause...)] = i
+		}
+	}
+	find := func(literals ...int) (int, bool) {
+		i, exists := index[clauseKey(literals...)]
+		return i, exists
+	}
+	gates := []Gate{}
+
+	// AND/OR: a long clause (x OR -a OR -b ...) with binary clauses (-x OR a), (-x OR b), ...
+	for i, clause := range cnf {
+		if len(clause) < 3 {
+			continue
+		}
+		for _, x := range clause {
+			clauses := []int{i}
+			inputs := []int{}
+			for _, l := range clause {
+				if l == x {
+					continue
+				}
+				j, exists := find(-x, -l)
+				if !exists {
+					break
+				}
+				clauses = append(clauses, j)
+				inputs = append(inputs, -l)
+			}
+			if len(inputs) != len(clause)-1 {
+				continue
+			}
+			if x > 0 {
+				gates = append(gates, Gate{Kind: GateAnd, Output: x, Inputs: inputs, Clauses: clauses})
+			} else { // -x = AND(inputs) means x = OR(-inputs)
+				for k := range inputs {
+					inputs[k] = -inputs[k]
+				}
+				gates = append(gates, Gate{Kind: GateOr, Output: -x, Inputs: inputs, Clauses: clauses})
+			}
+		}
+	}
+
+	// XOR: the four ternary clauses over the same variables with an odd (or all even) number of negations
+	seen := make(map[string]bool)
+	for _, clause := range cnf {
+		if len(clause) != 3 {
+			continue
+		}
+		vars := []int{abs(clause[0]), abs(clause[1]), abs(clause[2])}
+		sort.Ints(vars)
+		if vars[0] == vars[1] || vars[1] == vars[2] || seen[fmt.Sprint(vars)] {
+			continue
+		}
+		negations := 0
+		for _, l := range clause {
+			if l < 0 {
+				negations++
+			}
+		}
+		clauses := []int{}
+		for signs := 0; signs < 8; signs++ {
+			literals := make([]int, 3)
+			count := 0
+			for k, v := range vars {
+				literals[k] = v
+				if signs>>k&1 == 1 {
+					literals[k] = -v
+					count++
+				}
+			}
+			if count%2 != negations%2 {
+				continue
+			}
+			j, exists := find(literals...)
+			if !exists {
+				break
+			}
+			clauses = append(clauses, j)
+		}
+		if len(clauses) != 4 {
+			continue
+		}
+		seen[fmt.Sprint(vars)] = true
+		output := vars[2]
+		if negations%2 == 0 { // Clauses with even negations force an odd parity, so the output is negated
+			output = -output
+		}
+		gates = append(gates, Gate{Kind: GateXor, Output: output, Inputs: vars[:2], Clauses: clauses})
+	}
+
+	// ITE: (-o OR -c OR t), (-o OR c OR e), (o OR -c OR -t), (o OR c OR -e) with o and c positive
+	for _, clause := range cnf {
+		if len(clause) != 3 {
+			continue
+		}
+		for _, perm := range [][3]int{{0, 1, 2}, {0, 2, 1}, {1, 0, 2}, {1, 2, 0}, {2, 0, 1}, {2, 1, 0}} {
+			o, c, t := -clause[perm[0]], -clause[perm[1]], clause[perm[2]]
+			if o <= 0 || c <= 0 {
+				continue
+			}
+			for _, other := range cnf {
+				if len(other) != 3 || !containsLiteral(other, -o) || !containsLiteral(other, c) {
+					continue
+				}
+				e := 0
+				for _, l := range other {
+					if l != -o && l != c {
+						e = l
+					}
+				}
+				if e == 0 || abs(e) == abs(t) || abs(e) == o || abs(e) == c {
+					continue
+				}
+				first, _ := find(-o, -c, t)
+				second, _ := find(-o, c, e)
+				third, ok3 := find(o, -c, -t)
+				fourth, ok4 := find(o, c, -e)
+				if ok3 && ok4 && !hasGate(gates, GateITE, o) {
+					gates = append(gates, Gate{Kind: GateITE, Output: o, Inputs: []int{c, t, e}, Clauses: []int{first, second, third, fourth}})
+				}
+			}
+		}
+	}
+	return gates
+}
+
+// containsLiteral reports whether the clause contains the literal
+func containsLiteral(clause Clause, literal int) bool {
+	for _, l := range clause {
+		if l == literal {
+			return true
+		}
+	}
+	return false
+}
+
+// hasGate reports whether a gate of the given kind already defines output
+func hasGate(gates []Gate, kind GateKind, output int) bool {
+	for _, gate := range gates {
+		if gate.Kind == kind && gate.Output == output {
+			return true
+		}
+	}
+	return false
+}
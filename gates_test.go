@@ -0,0 +1,24 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectGates(t *testing.T) {
+	cnf := CNF{
+		{-3, 1}, {-3, 2}, {3, -1, -2}, // 3 = 1 AND 2
+		{-6, 4, 5}, {-6, -4, -5}, {6, -4, 5}, {6, 4, -5}, // 6 = 4 XOR 5
+	}
+	gates := DetectGates(cnf)
+	if len(gates) != 2 || !hasGate(gates, GateAnd, 3) || !hasGate(gates, GateXor, 6) ||
+		!reflect.DeepEqual(gates[0].Inputs, []int{1, 2}) || !reflect.DeepEqual(gates[1].Inputs, []int{4, 5}) {
+		t.Fatalf("got %+v", gates)
+	}
+}
+
+func TestGateKindString(t *testing.T) {
+	if GateITE.String() != "ITE" || GateKind(9).String() != "GateKind(9)" {
+		t.Fatal(GateITE.String(), GateKind(9).String())
+	}
+}
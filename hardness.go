@@ -0,0 +1,52 @@
+package main
+
+// satThresholds gives the clause-to-variable ratio at which random k-SAT instances go from almost
+// always satisfiable to almost always unsatisfiable, indexed by k
+var satThresholds = map[int]float64{3: 4.267, 4: 9.931, 5: 21.117, 6: 43.37, 7: 87.79}
+
+// hardBand is how far, relative to the threshold, the ratio may be for an instance to count as
+// near the phase transition, where random instances are hardest
+const hardBand = 0.1
+
+// HardnessReport summarises the structure of a CNF as a rough guide to how hard it is
+type HardnessReport struct {
+	Variables       int
+	Clauses         int
+	Ratio           float64 // Clauses per variable
+	MaxClauseLength int
+	AvgClauseLength float64
+	K               int     // The common clause length, or 0 when lengths differ
+	Threshold       float64 // Satisfiability threshold of random K-SAT, or 0 when unknown
+	LikelyHard      bool    // K-SAT with the ratio near the threshold
+}
+
+// EstimateHardness measures the CNF and compares it with random k-SAT: an instance whose clauses
+// all have k >= 3 literals is likely hard when its ratio lies within 10% of the satisfiability
+// threshold. Below it instances tend to be easy and satisfiable, above it easy to refute, and
+// 2-SAT is always easy. Structured instances can defy the estimate either way.
+func EstimateHardness(cnf CNF) HardnessReport {
+	report := HardnessReport{Variables: NumVariables(cnf), Clauses: len(cnf)}
+	if report.Clauses == 0 {
+		return report
+	}
+	literals := 0
+	report.K = len(cnf[0])
+	for _, clause := range cnf {
+		literals += len(clause)
+		if len(clause) > report.MaxClauseLength {
+			report.MaxClauseLength = len(clause)
+		}
+		if len(clause) != report.K {
+			report.K = 0
+		}
+	}
+	report.AvgClauseLength = float64(literals) / float64(report.Clauses)
+	if report.Variables > 0 {
+		report.Ratio = float64(report.Clauses) / float64(report.Variables)
+	}
+	if threshold, known := satThresholds[report.K]; known {
+		report.Threshold = threshold
+		report.LikelyHard = report.Ratio >= threshold*(1-hardBand) && report.Ratio <= threshold*(1+hardBand)
+	}
+	return report
+}
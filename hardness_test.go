@@ -0,0 +1,18 @@
+package main
+
+import "testing"
+
+func TestEstimateHardness(t *testing.T) {
+	if report := EstimateHardness(random3SAT(100, 426, 1)); !report.LikelyHard || report.K != 3 || report.MaxClauseLength != 3 || report.Threshold == 0 {
+		t.Errorf("random 3-SAT at the threshold: got %+v, want likely hard", report)
+	}
+	if report := EstimateHardness(random3SAT(100, 150, 1)); report.LikelyHard || report.Ratio > 2 {
+		t.Errorf("under-constrained random 3-SAT: got %+v, want likely easy", report)
+	}
+	if report := EstimateHardness(CNF{{1, 2}, {1, 2, 3}}); report.K != 0 || report.AvgClauseLength != 2.5 || report.LikelyHard {
+		t.Errorf("mixed clause lengths: got %+v", report)
+	}
+	if report := EstimateHardness(CNF{}); report.LikelyHard || report.Ratio != 0 {
+		t.Errorf("empty CNF: got %+v", report)
+	}
+}
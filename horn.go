@@ -0,0 +1,34 @@
+package main
+
+// IsHorn reports whether every clause has at most one positive literal
+func IsHorn(cnf CNF) bool {
+	for _, clause := range cnf {
+		positive := 0
+		for _, literal := range clause {
+			if literal > 0 {
+				positive++
+			}
+		}
+		if positive > 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// SolveHorn decides a Horn formula without branching: unit propagation either fails or reaches a
+// fixpoint where every remaining clause has a negative literal, so setting the free variables
+// to false satisfies it. The model assigns every variable of the CNF.
+func SolveHorn(cnf CNF) (bool, map[int]bool) {
+	cnf = resolveConstants(cnf)
+	assignment := make(map[int]bool)
+	if _, ok := UnitPropagation(cnf, assignment); !ok {
+		return false, nil
+	}
+	for _, variable := range Variables(cnf) {
+		if _, exists := assignment[variable]; !exists {
+			assignment[variable] = false
+		}
+	}
+	return true, assignment
+}
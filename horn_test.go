@@ -0,0 +1,45 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestSolveHorn(t *testing.T) {
+	if IsHorn(CNF{{1, 2, -3}}) || !IsHorn(CNF{{1, -2, -3}, {-1}, {}}) {
+		t.Fatal("IsHorn counts the positive literals of each clause")
+	}
+	// 1, 1 -> 2 and 2 & 3 -> 4: propagation forces 1 and 2, and the fixpoint leaves 3 and 4 false
+	satisfiable, model := SolveHorn(CNF{{1}, {-1, 2}, {-2, -3, 4}, {-4, -1}})
+	if want := map[int]bool{1: true, 2: true, 3: false, 4: false}; !satisfiable || !reflect.DeepEqual(model, want) {
+		t.Fatalf("got %v, %v, want the least model %v", satisfiable, model, want)
+	}
+
+	rng := rand.New(rand.NewSource(5))
+	for round := 0; round < 300; round++ {
+		n := 2 + rng.Intn(8)
+		cnf := CNF{}
+		for i := rng.Intn(3 * n); i >= 0; i-- {
+			clause := Clause{}
+			for k := 1 + rng.Intn(3); k > 0; k-- {
+				clause = append(clause, -(rng.Intn(n) + 1))
+			}
+			if rng.Intn(2) == 0 {
+				clause[0] = -clause[0]
+			}
+			cnf = append(cnf, clause)
+		}
+		want := DPLL(cnf, map[int]bool{})
+		got, model := SolveHorn(cnf)
+		if got != want {
+			t.Fatalf("%v: got %v, want %v", cnf, got, want)
+		}
+		if got && len(UnsatisfiedClauses(cnf, model)) > 0 {
+			t.Fatalf("%v: model %v falsifies a clause", cnf, model)
+		}
+		if satisfiable, _ := Solve(cnf); satisfiable != want {
+			t.Fatalf("%v: Solve got %v, want %v", cnf, satisfiable, want)
+		}
+	}
+}
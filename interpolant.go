@@ -0,0 +1,134 @@
+package main
+
+import "errors"
+
+// Interpolant computes a Craig interpolant of an unsatisfiable pair of clause groups: a CNF over
+// the variables shared by a and b that a implies and that contradicts b. It refutes a AND b with
+// a DPLL search that records the tree resolution proof behind each closed branch, and labels the
+// proof with McMillan's partial interpolants: clauses of a contribute their shared literals,
+// clauses of b contribute true, resolving on a variable local to a takes the disjunction and any
+// other resolution takes the conjunction.
+func Interpolant(a, b CNF) (CNF, error) {
+	inB := make(map[int]bool)
+	for _, variable := range Variables(b) {
+		inB[variable] = true
+	}
+	inA := make(map[int]bool)
+	for _, variable := range Variables(a) {
+		inA[variable] = true
+	}
+	clauses := append(append([]Clause{}, a...), b...)
+	r := refutation{clauses: clauses, numA: len(a), inA: inA, inB: inB}
+	_, interpolant, ok := r.refute(make(map[int]bool))
+	if !ok {
+		return nil, errors.New("interpolant: a and b are satisfiable together")
+	}
+	return interpolant, nil
+}
+
+// refutation is the state of the proof-producing search behind Interpolant
+type refutation struct {
+	clauses  []Clause // Clauses of a followed by those of b
+	numA     int
+	inA, inB map[int]bool // Variables occurring in a and in b
+}
+
+// refute closes the branch of the given partial assignment: it returns a clause derived by
+// resolution that the assignment falsifies, with its partial interpolant. It reports false when the
+// assignment extends to a model.
+func (r *refutation) refute(assignment map[int]bool) (Clause, CNF, bool) {
+	branch, unit := 0, 0
+	for i, clause := range r.clauses {
+		if clauseSatisfied(clause, assignment) {
+			continue
+		}
+		free := []int{}
+		for _, literal := range clause {
+			if _, exists := assignment[abs(literal)]; !exists {
+				free = append(free, literal)
+			}
+		}
+		if len(free) == 0 { // Leaf of the proof: an input clause
+			if i >= r.numA {
+				return clause, CNF{}, true
+			}
+			shared := Clause{}
+			for _, literal := range clause {
+				if r.inB[abs(literal)] {
+					shared = append(shared, literal)
+				}
+			}
+			return clause, CNF{shared}, true
+		}
+		if len(free) == 1 && unit == 0 {
+			unit = free[0]
+		}
+		if branch == 0 {
+			branch = free[0]
+		}
+	}
+	if unit != 0 {
+		branch = unit // Falsifying the unit first closes that side at once, like propagation
+	} else if branch == 0 {
+		return nil, nil, false // Every clause is satisfied
+	}
+
+	variable := abs(branch)
+	closed := [2]Clause{}
+	partial := [2]CNF{}
+	for side, value := range []bool{branch < 0, branch > 0} {
+		assignment[variable] = value
+		clause, interpolant, ok := r.refute(assignment)
+		delete(assignment, variable)
+		if !ok {
+			return nil, nil, false
+		}
+		falsified := variable // The literal this side's value makes false
+		if value {
+			falsified = -variable
+		}
+		if !containsLiteral(clause, falsified) {
+			return clause, interpolant, true // The clause does not depend on the branch
+		}
+		closed[side], partial[side] = clause, interpolant
+	}
+
+	resolvent := Clause{}
+	for _, clause := range closed {
+		for _, literal := range clause {
+			if abs(literal) != variable && !containsLiteral(resolvent, literal) {
+				resolvent = append(resolvent, literal)
+			}
+		}
+	}
+	if r.inA[variable] && !r.inB[variable] {
+		return resolvent, orCNF(partial[0], partial[1]), true
+	}
+	return resolvent, append(append(CNF{}, partial[0]...), partial[1]...), true
+}
+
+// orCNF returns a CNF equivalent to the disjunction of two CNFs by distributing one over the other
+func orCNF(p, q CNF) CNF {
+	result := CNF{}
+	seen := make(map[string]bool)
+	for _, c1 := range p {
+		for _, c2 := range q {
+			clause := append(Clause{}, c1...)
+			tautology := false
+			for _, literal := range c2 {
+				if containsLiteral(clause, -literal) {
+					tautology = true
+					break
+				}
+				if !containsLiteral(clause, literal) {
+					clause = append(clause, literal)
+				}
+			}
+			if key := clauseKey(clause...); !tautology && !seen[key] {
+				seen[key] = true
+				result = append(result, clause)
+			}
+		}
+	}
+	return result
+}
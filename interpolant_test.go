@@ -0,0 +1,78 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// checkInterpolant verifies that the interpolant of a and b only uses their shared variables,
+// is implied by a and contradicts b
+func checkInterpolant(t *testing.T, a, b CNF) {
+	t.Helper()
+	interpolant, err := Interpolant(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inA := make(map[int]bool)
+	for _, variable := range Variables(a) {
+		inA[variable] = true
+	}
+	inB := make(map[int]bool)
+	for _, variable := range Variables(b) {
+		inB[variable] = true
+	}
+	for _, variable := range Variables(interpolant) {
+		if !inA[variable] || !inB[variable] {
+			t.Fatalf("%v / %v: interpolant %v uses the unshared variable %d", a, b, interpolant, variable)
+		}
+	}
+	if DPLL(append(append(CNF{}, interpolant...), b...), map[int]bool{}) {
+		t.Fatalf("%v / %v: interpolant %v is consistent with b", a, b, interpolant)
+	}
+	for _, clause := range interpolant {
+		refuted := append(CNF{}, a...)
+		for _, literal := range clause {
+			refuted = append(refuted, Clause{-literal})
+		}
+		if DPLL(refuted, map[int]bool{}) {
+			t.Fatalf("%v / %v: a does not imply %v of the interpolant", a, b, clause)
+		}
+	}
+}
+
+func TestInterpolant(t *testing.T) {
+	// a says 1 and 1 -> 2, b says 2 -> 3 and -3: the only shared variable is 2
+	a, b := CNF{{1}, {-1, 2}}, CNF{{-2, 3}, {-3}}
+	checkInterpolant(t, a, b)
+	if interpolant, _ := Interpolant(a, b); len(interpolant) != 1 || len(interpolant[0]) != 1 || interpolant[0][0] != 2 {
+		t.Fatalf("got %v, want (2)", interpolant)
+	}
+	if _, err := Interpolant(CNF{{1}}, CNF{{1, 2}}); err == nil {
+		t.Fatal("a satisfiable pair has no interpolant")
+	}
+
+	rng := rand.New(rand.NewSource(17))
+	group := func(low, high int) CNF {
+		cnf := CNF{}
+		for i := 0; i < 8; i++ {
+			clause := Clause{}
+			for k := 2 + rng.Intn(2); k > 0; k-- {
+				literal := low + rng.Intn(high-low+1)
+				if rng.Intn(2) == 0 {
+					literal = -literal
+				}
+				clause = append(clause, literal)
+			}
+			cnf = append(cnf, clause)
+		}
+		return cnf
+	}
+	for round, found := 0, 0; round < 400 && found < 60; round++ {
+		a, b := group(1, 5), group(3, 7)
+		if DPLL(append(append(CNF{}, a...), b...), map[int]bool{}) {
+			continue
+		}
+		found++
+		checkInterpolant(t, a, b)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// IsIsomorphic reports whether b is a with its variables renamed, comparing both as multisets
+// of clauses, and returns the renaming from the variables of a to those of b when it is. The
+// search tries, variable by variable, only the candidates with the same signature (occurrences
+// per polarity and the lengths of the clauses they occur in), and backtracks as soon as a
+// clause whose variables are all renamed has no image in b.
+func IsIsomorphic(a, b CNF) (bool, map[int]int) {
+	a, b = canonicalClauses(a), canonicalClauses(b)
+	if len(a) != len(b) || NumVariables(a) != NumVariables(b) {
+		return false, nil
+	}
+	target := make(map[string]int)
+	for _, clause := range b {
+		target[clauseKey(clause...)]++
+	}
+	signaturesB := variableSignatures(b)
+	byVariable := make(map[int][]Clause)
+	for _, clause := range a {
+		for _, literal := range clause {
+			byVariable[abs(literal)] = append(byVariable[abs(literal)], clause)
+		}
+	}
+	variables := Variables(a)
+	candidates := make(map[int][]int)
+	for variable, signature := range variableSignatures(a) {
+		for other, otherSignature := range signaturesB {
+			if signature == otherSignature {
+				candidates[variable] = append(candidates[variable], other)
+			}
+		}
+		if len(candidates[variable]) == 0 {
+			return false, nil
+		}
+		sort.Ints(candidates[variable])
+	}
+	sort.SliceStable(variables, func(i, j int) bool { // Most constrained first
+		return len(candidates[variables[i]]) < len(candidates[variables[j]])
+	})
+
+	mapping := make(map[int]int)
+	used := make(map[int]bool)
+	var search func(i int) bool
+	search = func(i int) bool {
+		if i == len(variables) {
+			images := make(map[string]int)
+			for _, clause := range a {
+				images[clauseKey(renameClause(clause, mapping)...)]++
+			}
+			for key, count := range target {
+				if images[key] != count {
+					return false
+				}
+			}
+			return true
+		}
+		variable := variables[i]
+		for _, candidate := range candidates[variable] {
+			if used[candidate] {
+				continue
+			}
+			mapping[variable], used[candidate] = candidate, true
+			if imagesExist(byVariable[variable], mapping, target) && search(i+1) {
+				return true
+			}
+			delete(mapping, variable)
+			used[candidate] = false
+		}
+		return false
+	}
+	if !search(0) {
+		return false, nil
+	}
+	return true, mapping
+}
+
+// imagesExist reports whether every clause whose variables are all renamed by the mapping
+// renames to a clause of the target
+func imagesExist(clauses []Clause, mapping map[int]int, target map[string]int) bool {
+	for _, clause := range clauses {
+		complete := true
+		for _, literal := range clause {
+			if _, exists := mapping[abs(literal)]; !exists {
+				complete = false
+				break
+			}
+		}
+		if complete && target[clauseKey(renameClause(clause, mapping)...)] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// renameClause renames the variables of the clause by the mapping, keeping their signs
+func renameClause(clause Clause, mapping map[int]int) Clause {
+	renamed := make(Clause, len(clause))
+	for i, literal := range clause {
+		renamed[i] = mapping[abs(literal)]
+		if literal < 0 {
+			renamed[i] = -renamed[i]
+		}
+	}
+	return renamed
+}
+
+// canonicalClauses returns the clauses of the CNF with constants resolved and repeated
+// literals removed
+func canonicalClauses(cnf CNF) CNF {
+	canonical := CNF{}
+	for _, clause := range resolveConstants(cnf) {
+		distinct := Clause{}
+		for i, literal := range clause {
+			if !containsLiteral(clause[:i], literal) {
+				distinct = append(distinct, literal)
+			}
+		}
+		canonical = append(canonical, distinct)
+	}
+	return canonical
+}
+
+// variableSignatures summarises each variable by the lengths of the clauses it occurs in,
+// positively and negatively; a renaming can only map a variable to one with the same signature
+func variableSignatures(cnf CNF) map[int]string {
+	lengths := make(map[int][]int)
+	for _, clause := range cnf {
+		for _, literal := range clause {
+			lengths[literal] = append(lengths[literal], len(clause))
+		}
+	}
+	signatures := make(map[int]string)
+	for _, variable := range Variables(cnf) {
+		positive, negative := lengths[variable], lengths[-variable]
+		sort.Ints(positive)
+		sort.Ints(negative)
+		signatures[variable] = fmt.Sprint(positive, negative)
+	}
+	return signatures
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// renamed returns the CNF with its variables renamed by the mapping and its clauses and literals
+// reversed, so that only a renaming relates it to the original
+func renamed(cnf CNF, mapping map[int]int) CNF {
+	result := CNF{}
+	for i := len(cnf) - 1; i >= 0; i-- {
+		clause := renameClause(cnf[i], mapping)
+		for j, k := 0, len(clause)-1; j < k; j, k = j+1, k-1 {
+			clause[j], clause[k] = clause[k], clause[j]
+		}
+		result = append(result, clause)
+	}
+	return result
+}
+
+// sameClauses reports whether the CNFs have the same clauses, as multisets
+func sameClauses(a, b CNF) bool {
+	counts := make(map[string]int)
+	for _, clause := range a {
+		counts[clauseKey(clause...)]++
+	}
+	for _, clause := range b {
+		counts[clauseKey(clause...)]--
+	}
+	for _, count := range counts {
+		if count != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestIsIsomorphic(t *testing.T) {
+	a := CNF{{1, -2, 3}, {-1, 2}, {2, 3}, {-3, 4}, {1, 4}}
+	want := map[int]int{1: 7, 2: 3, 3: 9, 4: 1}
+	b := renamed(a, want)
+	isomorphic, mapping := IsIsomorphic(a, b)
+	if !isomorphic || !reflect.DeepEqual(mapping, want) {
+		t.Fatalf("got %v, %v, want the mapping %v", isomorphic, mapping, want)
+	}
+	if isomorphic, mapping := IsIsomorphic(a, append(b[:4:4], Clause{-1, 9})); isomorphic {
+		t.Errorf("a changed clause must break the isomorphism, got the mapping %v", mapping)
+	}
+	if isomorphic, _ := IsIsomorphic(CNF{{1, 2}, {1, 2}}, CNF{{1, 2}, {3, 4}}); isomorphic {
+		t.Error("a repeated clause must be matched by a repeated clause")
+	}
+
+	rng := rand.New(rand.NewSource(2))
+	for n := 0; n < 100; n++ {
+		cnf := CNF{}
+		for i := 0; i < 10; i++ {
+			clause := Clause{}
+			for j := 0; j < 3; j++ {
+				literal := 1 + rng.Intn(8)
+				if rng.Intn(2) == 0 {
+					literal = -literal
+				}
+				clause = append(clause, literal)
+			}
+			cnf = append(cnf, clause)
+		}
+		permutation := make(map[int]int)
+		for i, image := range rng.Perm(20)[:8] {
+			permutation[i+1] = image + 1
+		}
+		other := renamed(Shuffle(cnf, int64(n)), permutation)
+		isomorphic, mapping := IsIsomorphic(cnf, other)
+		if !isomorphic {
+			t.Fatalf("%v and %v are renamings of each other", cnf, other)
+		}
+		if !sameClauses(renamed(canonicalClauses(cnf), mapping), canonicalClauses(other)) {
+			t.Fatalf("the mapping %v does not rename %v to %v", mapping, cnf, other)
+		}
+	}
+}
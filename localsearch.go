@@ -0,0 +1,203 @@
+package main
+
+import "math/rand"
+
+// walkSATNoise is the probability that WalkSAT flips a random variable of the chosen clause
+const walkSATNoise = 0.5
+
+// MakeCount tracks which clauses of a CNF a complete assignment falsifies while variables are
+// flipped one at a time, as local search needs: a flip only revisits the clauses containing the
+// variable, and the falsified clauses are always at hand.
+type MakeCount struct {
+	values      map[int]bool
+	occurrences map[int][]int // Literal to the clauses containing it
+	numTrue     []int         // True literals of each clause
+	unsat       []int         // Falsified clauses
+	position    map[int]int   // Index of each falsified clause in unsat
+}
+
+// NewMakeCount starts tracking the CNF under the assignment, which is copied; variables it
+// leaves out are false
+func NewMakeCount(cnf CNF, assignment map[int]bool) *MakeCount {
+	m := &MakeCount{
+		values:      make(map[int]bool),
+		occurrences: make(map[int][]int),
+		numTrue:     make([]int, len(cnf)),
+		position:    make(map[int]int),
+	}
+	for _, variable := range Variables(cnf) {
+		m.values[variable] = assignment[variable]
+	}
+	for i, clause := range cnf {
+		for j, literal := range clause {
+			if containsLiteral(clause[:j], literal) {
+				continue // Repeated literals count once
+			}
+			m.occurrences[literal] = append(m.occurrences[literal], i)
+			if m.values[abs(literal)] == (literal > 0) {
+				m.numTrue[i]++
+			}
+		}
+		if m.numTrue[i] == 0 {
+			m.position[i] = len(m.unsat)
+			m.unsat = append(m.unsat, i)
+		}
+	}
+	return m
+}
+
+// Flip changes the value of the variable and updates the falsified clauses containing it
+func (m *MakeCount) Flip(variable int) {
+	literal := variable // The literal the flip makes true
+	if m.values[variable] {
+		literal = -variable
+	}
+	m.values[variable] = literal > 0
+	for _, i := range m.occurrences[literal] {
+		m.numTrue[i]++
+		if m.numTrue[i] == 1 {
+			last := m.unsat[len(m.unsat)-1]
+			m.unsat[m.position[i]], m.position[last] = last, m.position[i]
+			m.unsat = m.unsat[:len(m.unsat)-1]
+			delete(m.position, i)
+		}
+	}
+	for _, i := range m.occurrences[-literal] {
+		m.numTrue[i]--
+		if m.numTrue[i] == 0 {
+			m.position[i] = len(m.unsat)
+			m.unsat = append(m.unsat, i)
+		}
+	}
+}
+
+// NumUnsatisfied returns the number of clauses the current assignment falsifies
+func (m *MakeCount) NumUnsatisfied() int {
+	return len(m.unsat)
+}
+
+// Unsatisfied returns the indices of the falsified clauses, in no particular order. The slice is
+// shared and changes with the next Flip.
+func (m *MakeCount) Unsatisfied() []int {
+	return m.unsat
+}
+
+// Make returns the number of falsified clauses that flipping the variable would satisfy
+func (m *MakeCount) Make(variable int) int {
+	literal := variable
+	if m.values[variable] {
+		literal = -variable
+	}
+	makes := 0
+	for _, i := range m.occurrences[literal] {
+		if m.numTrue[i] == 0 {
+			makes++
+		}
+	}
+	return makes
+}
+
+// Break returns the number of satisfied clauses that flipping the variable would falsify
+func (m *MakeCount) Break(variable int) int {
+	literal := variable // The literal the flip makes false
+	if !m.values[variable] {
+		literal = -variable
+	}
+	breaks := 0
+	for _, i := range m.occurrences[literal] {
+		if m.numTrue[i] == 1 {
+			breaks++
+		}
+	}
+	return breaks
+}
+
+// Assignment returns a copy of the current assignment
+func (m *MakeCount) Assignment() map[int]bool {
+	assignment := make(map[int]bool, len(m.values))
+	for variable, value := range m.values {
+		assignment[variable] = value
+	}
+	return assignment
+}
+
+// walkSAT searches for a model of the CNF by local search: starting from a random assignment it
+// repeatedly picks a falsified clause and flips one of its variables, either at random or the one
+// that falsifies the fewest other clauses. It gives up after maxFlips flips.
+func walkSAT(cnf CNF, maxFlips int, rng *rand.Rand) (map[int]bool, bool) {
+	for _, clause := range cnf {
+		if len(clause) == 0 {
+			return nil, false
+		}
+	}
+	values := make(map[int]bool)
+	for _, variable := range Variables(cnf) {
+		values[variable] = rng.Intn(2) == 1
+	}
+	m := NewMakeCount(cnf, values)
+	for flips := 0; flips < maxFlips && m.NumUnsatisfied() > 0; flips++ {
+		clause := cnf[m.unsat[rng.Intn(len(m.unsat))]]
+		variable := abs(clause[rng.Intn(len(clause))])
+		if rng.Float64() >= walkSATNoise {
+			best := -1
+			for _, candidate := range clause {
+				if breaks := m.Break(abs(candidate)); best == -1 || breaks < best {
+					variable, best = abs(candidate), breaks
+				}
+			}
+		}
+		m.Flip(variable)
+	}
+	if m.NumUnsatisfied() > 0 {
+		return nil, false
+	}
+	return m.Assignment(), true
+}
+
+// GSAT searches for a model of the CNF by greedy local search: each try starts from a random
+// assignment of the variables 1..numVars and, for up to maxFlips steps, flips the variable that
+// most decreases the number of falsified clauses, even when none decreases it, choosing at random
+// among equally good variables. It gives up after maxTries tries.
+func GSAT(cnf CNF, numVars, maxFlips, maxTries int, seed int64) (bool, map[int]bool) {
+	cnf = resolveConstants(cnf)
+	for _, clause := range cnf {
+		if len(clause) == 0 {
+			return false, nil
+		}
+	}
+	if v := maxVariable(cnf, nil); v > numVars {
+		numVars = v
+	}
+	rng := rand.New(rand.NewSource(seed))
+	variables := Variables(cnf)
+	for try := 0; try < maxTries; try++ {
+		values := make(map[int]bool)
+		for variable := 1; variable <= numVars; variable++ {
+			values[variable] = rng.Intn(2) == 1
+		}
+		m := NewMakeCount(cnf, values)
+		for flips := 0; flips < maxFlips && m.NumUnsatisfied() > 0; flips++ {
+			best, bestScore, ties := 0, 0, 0
+			for _, variable := range variables {
+				score := m.Make(variable) - m.Break(variable)
+				switch {
+				case best == 0 || score > bestScore:
+					best, bestScore, ties = variable, score, 1
+				case score == bestScore:
+					ties++
+					if rng.Intn(ties) == 0 { // Every tied variable is equally likely to stay chosen
+						best = variable
+					}
+				}
+			}
+			m.Flip(best)
+		}
+		if m.NumUnsatisfied() == 0 {
+			for variable, value := range m.Assignment() {
+				values[variable] = value
+			}
+			return true, values
+		}
+	}
+	return false, nil
+}
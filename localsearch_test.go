@@ -0,0 +1,53 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestMakeCount(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	cnf := append(random3SAT(30, 120, 7), Clause{1, 1}, Clause{2, -3, 2})
+	assignment := make(map[int]bool)
+	for variable := 1; variable <= 30; variable++ {
+		assignment[variable] = rng.Intn(2) == 0
+	}
+	m := NewMakeCount(cnf, assignment)
+	for step := 0; step < 500; step++ {
+		variable := 1 + rng.Intn(30)
+		before, makes, breaks := m.NumUnsatisfied(), m.Make(variable), m.Break(variable)
+		m.Flip(variable)
+		assignment[variable] = !assignment[variable]
+		want := len(UnsatisfiedClauses(cnf, assignment))
+		if m.NumUnsatisfied() != want || len(m.Unsatisfied()) != want {
+			t.Fatalf("step %d: got %d unsatisfied clauses, want %d", step, m.NumUnsatisfied(), want)
+		}
+		if before-makes+breaks != want {
+			t.Fatalf("step %d: %d unsatisfied, make %d and break %d do not lead to %d", step, before, makes, breaks, want)
+		}
+	}
+	if got := m.Assignment(); !reflect.DeepEqual(got, assignment) {
+		t.Fatalf("got the assignment %v, want %v", got, assignment)
+	}
+}
+
+func TestGSAT(t *testing.T) {
+	cnf := random3SAT(40, 120, 3)
+	if satisfiable, _ := Solve(cnf); !satisfiable {
+		t.Fatal("the instance must be satisfiable")
+	}
+	satisfiable, model := GSAT(cnf, 45, 500, 10, 1)
+	if !satisfiable || len(model) != 45 || len(UnsatisfiedClauses(cnf, model)) != 0 {
+		t.Fatalf("got %v with %d variables, want a model of 45", satisfiable, len(model))
+	}
+	if _, again := GSAT(cnf, 45, 500, 10, 1); !reflect.DeepEqual(again, model) {
+		t.Fatal("the same seed must give the same model")
+	}
+	if satisfiable, _ := GSAT(CNF{{1}, {-1}}, 1, 50, 3, 1); satisfiable {
+		t.Error("found a model of an unsatisfiable CNF")
+	}
+	if satisfiable, model := GSAT(CNF{}, 2, 5, 1, 1); !satisfiable || len(model) != 2 {
+		t.Errorf("empty CNF: got %v, %v", satisfiable, model)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+// lookaheadCandidates is the number of most frequent variables evaluated at each node
+const lookaheadCandidates = 10
+
+// SolveLookahead decides the CNF with a lookahead DPLL: at each node it trial-propagates both
+// polarities of the most frequent variables, fixes failed literals, and branches on the variable
+// whose two propagations reduce the formula the most. It returns a complete model when satisfiable,
+// and statistics whose Decisions count the branches taken and Conflicts the failed nodes;
+// trial propagations are not counted.
+func SolveLookahead(cnf CNF) (bool, map[int]bool, Stats) {
+	cnf = resolveConstants(cnf)
+	start := time.Now()
+	var stats Stats
+	assignment := make(map[int]bool)
+	satisfiable := lookahead(cnf, assignment, &stats)
+	stats.SolveTime = time.Since(start)
+	if !satisfiable {
+		return false, nil, stats
+	}
+	return true, CompleteAssignment(cnf, assignment), stats
+}
+
+// lookahead is the recursive search behind SolveLookahead
+func lookahead(cnf CNF, assignment map[int]bool, stats *Stats) bool {
+	for _, clause := range cnf {
+		if len(clause) == 0 {
+			stats.Conflicts++
+			return false
+		}
+	}
+	cnf, ok := UnitPropagation(cnf, assignment)
+	if !ok {
+		stats.Conflicts++
+		return false
+	}
+	if len(cnf) == 0 {
+		return true
+	}
+
+	frequency := VariableFrequency(cnf)
+	candidates := Variables(cnf)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return frequency[candidates[i]] > frequency[candidates[j]]
+	})
+	if len(candidates) > lookaheadCandidates {
+		candidates = candidates[:lookaheadCandidates]
+	}
+
+	best, bestScore, bestValue := 0, -1.0, true
+	for _, variable := range candidates {
+		var reductions [2]float64
+		for i, value := range []bool{true, false} {
+			reduction, ok := lookaheadReduction(cnf, variable, value)
+			if !ok { // Failed literal: the other polarity is forced
+				assignment[variable] = !value
+				return lookahead(assign(cnf, variable, !value), assignment, stats)
+			}
+			reductions[i] = reduction
+		}
+		// Prefer variables that reduce the formula in both branches
+		score := 1024*reductions[0]*reductions[1] + reductions[0] + reductions[1]
+		if score > bestScore {
+			// Explore the less constrained branch first as it is more likely to be satisfiable
+			best, bestScore, bestValue = variable, score, reductions[0] <= reductions[1]
+		}
+	}
+
+	stats.Decisions++
+	assignment[best] = bestValue
+	if lookahead(assign(cnf, best, bestValue), assignment, stats) {
+		return true
+	}
+	assignment[best] = !bestValue
+	return lookahead(assign(cnf, best, !bestValue), assignment, stats)
+}
+
+// lookaheadReduction propagates variable = value and weighs the clauses it shortens without
+// satisfying: 1 for each new binary clause and 0.2 for each other shortened clause.
+// It reports false when the propagation ends in a conflict.
+func lookaheadReduction(cnf CNF, variable int, value bool) (float64, bool) {
+	trial := map[int]bool{variable: value}
+	if _, ok := UnitPropagation(assign(cnf, variable, value), trial); !ok {
+		return 0, false
+	}
+	reduction := 0.0
+	for _, clause := range cnf {
+		if clauseSatisfied(clause, trial) {
+			continue
+		}
+		free := 0
+		for _, literal := range clause {
+			if _, exists := trial[abs(literal)]; !exists {
+				free++
+			}
+		}
+		if free == 2 && len(clause) > 2 {
+			reduction++
+		} else if free < len(clause) {
+			reduction += 0.2
+		}
+	}
+	return reduction, true
+}
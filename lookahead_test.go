@@ -0,0 +1,84 @@
+package main
+
+import "testing"
+
+// pigeonhole returns the unsatisfiable formula placing holes+1 pigeons into holes holes,
+// over the variables offset+1 onwards
+func pigeonhole(holes, offset int) CNF {
+	variable := func(pigeon, hole int) int { return offset + pigeon*holes + hole + 1 }
+	cnf := CNF{}
+	for pigeon := 0; pigeon <= holes; pigeon++ {
+		clause := Clause{}
+		for hole := 0; hole < holes; hole++ {
+			clause = append(clause, variable(pigeon, hole))
+		}
+		cnf = append(cnf, clause)
+	}
+	for hole := 0; hole < holes; hole++ {
+		for p := 0; p <= holes; p++ {
+			for q := p + 1; q <= holes; q++ {
+				cnf = append(cnf, Clause{-variable(p, hole), -variable(q, hole)})
+			}
+		}
+	}
+	return cnf
+}
+
+// dpllDecisions follows the search of DPLL on the CNF and returns whether it is satisfiable and
+// the number of branches taken
+func dpllDecisions(cnf CNF) (bool, int) {
+	for _, clause := range cnf {
+		if len(clause) == 0 {
+			return false, 0
+		}
+	}
+	cnf, ok := UnitPropagation(cnf, map[int]bool{})
+	if !ok {
+		return false, 0
+	}
+	if cnf = PureLiteralElimination(cnf, map[int]bool{}); len(cnf) == 0 {
+		return true, 0
+	}
+	decisions := 0
+	for _, value := range []bool{true, false} {
+		satisfiable, n := dpllDecisions(assign(cnf, abs(cnf[0][0]), value))
+		decisions += n + 1
+		if satisfiable {
+			return true, decisions
+		}
+	}
+	return false, decisions
+}
+
+func TestSolveLookaheadDecisions(t *testing.T) {
+	// DPLL branches on the low variables first, which are all equal, and repeats the refutation
+	// of the pigeonhole core under both of their values; lookahead goes for the core at once
+	cnf := CNF{}
+	for variable := 1; variable < 8; variable++ {
+		cnf = append(cnf, Clause{variable, -(variable + 1)}, Clause{-variable, variable + 1})
+	}
+	cnf = append(cnf, pigeonhole(3, 8)...)
+
+	satisfiable, model, stats := SolveLookahead(cnf)
+	if satisfiable || model != nil {
+		t.Fatal("the pigeonhole core is unsatisfiable")
+	}
+	if _, decisions := dpllDecisions(cnf); stats.Decisions == 0 || stats.Decisions >= decisions {
+		t.Fatalf("lookahead made %d decisions, DPLL %d", stats.Decisions, decisions)
+	}
+}
+
+func TestSolveLookahead(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		cnf := random3SAT(20, 85, seed)
+		satisfiable, model, _ := SolveLookahead(cnf)
+		if want := DPLL(cnf, map[int]bool{}); satisfiable != want {
+			t.Fatalf("seed %d: got %v, want %v", seed, satisfiable, want)
+		}
+		for _, clause := range cnf {
+			if satisfiable && !clauseSatisfied(clause, model) {
+				t.Fatalf("seed %d: the model falsifies %v", seed, clause)
+			}
+		}
+	}
+}
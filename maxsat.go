@@ -0,0 +1,199 @@
+package main
+
+// WeightedClause is a soft clause whose weight is paid when it is falsified
+type WeightedClause struct {
+	Weight int
+	Clause Clause
+}
+
+// clauseSatisfied reports whether some literal of the clause is true under the assignment
+func clauseSatisfied(clause Clause, assignment map[int]bool) bool {
+	for _, literal := range clause {
+		if value, exists := assignment[abs(literal)]; exists && value == (literal > 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// softCost returns the total weight of the soft clauses falsified by the assignment
+func softCost(soft []WeightedClause, assignment map[int]bool) int {
+	cost := 0
+	for _, wc := range soft {
+		if !clauseSatisfied(wc.Clause, assignment) {
+			cost += wc.Weight
+		}
+	}
+	return cost
+}
+
+// UnsatisfiedClauses returns the indices of the clauses falsified by the assignment, that is
+// those whose literals are all assigned false. Clauses with an unassigned literal are not reported.
+func UnsatisfiedClauses(cnf CNF, assignment map[int]bool) []int {
+	indices := []int{}
+	for i, clause := range cnf {
+		falsified := true
+		for _, literal := range clause {
+			if value, exists := assignment[abs(literal)]; !exists || value == (literal > 0) {
+				falsified = false
+				break
+			}
+		}
+		if falsified {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// maxVariable returns the largest variable used by the hard and soft clauses
+func maxVariable(hard CNF, soft []WeightedClause) int {
+	max := 0
+	for _, clause := range hard {
+		for _, literal := range clause {
+			if abs(literal) > max {
+				max = abs(literal)
+			}
+		}
+	}
+	for _, wc := range soft {
+		for _, literal := range wc.Clause {
+			if abs(literal) > max {
+				max = abs(literal)
+			}
+		}
+	}
+	return max
+}
+
+// solveModel runs DPLL on the CNF and returns a complete model when it is satisfiable
+func solveModel(cnf CNF) (bool, map[int]bool) {
+	assignment := make(map[int]bool)
+	if !DPLL(cnf, assignment) {
+		return false, nil
+	}
+	return true, CompleteAssignment(cnf, assignment)
+}
+
+// MaxSATCoreGuided minimizes the weight of falsified soft clauses subject to the hard clauses
+// with the core-guided WPM1 algorithm: it hardens all soft clauses, extracts a minimal
+// unsatisfiable core of them, relaxes the core with fresh variables of which exactly one may
+// be true (splitting weights so each round costs the smallest weight in the core) and repeats
+// until the formula is satisfiable. It returns a model over the original variables and its cost,
+// or nil and -1 when the hard clauses are unsatisfiable.
+func MaxSATCoreGuided(hard CNF, soft []WeightedClause) (map[int]bool, int) {
+	numVars := maxVariable(hard, soft)
+	nextVar := numVars
+	working := []WeightedClause{}
+	for _, wc := range soft {
+		if wc.Weight > 0 { // Free clauses never add to the cost
+			working = append(working, wc)
+		}
+	}
+	constraints := append(CNF{}, hard...) // Hard clauses plus relaxation constraints
+
+	for {
+		cnf := append(CNF{}, constraints...)
+		for _, wc := range working {
+			cnf = append(cnf, wc.Clause)
+		}
+		if ok, model := solveModel(cnf); ok {
+			result := make(map[int]bool)
+			for variable, value := range model {
+				if variable <= numVars {
+					result[variable] = value
+				}
+			}
+			return result, softCost(soft, result)
+		}
+
+		// Shrink the set of hardened soft clauses to a minimal core by deletion
+		core := make([]bool, len(working))
+		for i := range core {
+			core[i] = true
+		}
+		for i := range working {
+			core[i] = false
+			cnf := append(CNF{}, constraints...)
+			for j, wc := range working {
+				if core[j] {
+					cnf = append(cnf, wc.Clause)
+				}
+			}
+			if ok, _ := solveModel(cnf); ok {
+				core[i] = true // Clause i is needed for the conflict
+			}
+		}
+		minWeight := 0
+		for i, wc := range working {
+			if core[i] && (minWeight == 0 || wc.Weight < minWeight) {
+				minWeight = wc.Weight
+			}
+		}
+		if minWeight == 0 { // The hard clauses alone are unsatisfiable
+			return nil, -1
+		}
+
+		// Relax every core clause with its own variable, keeping any weight above minWeight unrelaxed
+		relaxation := []int{}
+		for i, wc := range working {
+			if !core[i] {
+				continue
+			}
+			nextVar++
+			relaxation = append(relaxation, nextVar)
+			relaxed := WeightedClause{Weight: minWeight, Clause: append(append(Clause{}, wc.Clause...), nextVar)}
+			if wc.Weight > minWeight {
+				working[i].Weight -= minWeight
+				working = append(working, relaxed)
+			} else {
+				working[i] = relaxed
+			}
+		}
+		ones := make([]int, len(relaxation))
+		for i := range ones {
+			ones[i] = 1
+		}
+		constraints = append(constraints, EncodePBEq(ones, relaxation, 1, &nextVar)...)
+	}
+}
+
+// MaxSATAnytime minimizes the weight of falsified soft clauses by linear search: each model
+// found tightens a pseudo-Boolean bound on the cost until no better model exists.
+// Every improved model is passed to onImprove with its satisfied soft weight, and the search
+// stops early with the best model so far when onImprove returns false. It returns the model
+// and its cost, or nil and -1 when the hard clauses are unsatisfiable.
+func MaxSATAnytime(hard CNF, soft []WeightedClause, onImprove func(satisfiedWeight int, model map[int]bool) bool) (map[int]bool, int) {
+	numVars := maxVariable(hard, soft)
+	nextVar := numVars
+	cnf := append(CNF{}, hard...)
+	weights, relaxation := []int{}, []int{}
+	total := 0
+	for _, wc := range soft {
+		nextVar++
+		cnf = append(cnf, append(append(Clause{}, wc.Clause...), nextVar))
+		weights = append(weights, wc.Weight)
+		relaxation = append(relaxation, nextVar)
+		total += wc.Weight
+	}
+
+	var best map[int]bool
+	bestCost := -1
+	for {
+		ok, model := solveModel(cnf)
+		if !ok {
+			return best, bestCost
+		}
+		best = make(map[int]bool)
+		for variable, value := range model {
+			if variable <= numVars {
+				best[variable] = value
+			}
+		}
+		bestCost = softCost(soft, best)
+		if onImprove != nil && !onImprove(total-bestCost, best) || bestCost == 0 {
+			return best, bestCost
+		}
+		cnf = append(cnf, EncodePBLeq(weights, relaxation, bestCost-1, &nextVar)...)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// hardSatisfied reports whether the assignment satisfies every hard clause
+func hardSatisfied(hard CNF, assignment map[int]bool) bool {
+	for _, clause := range hard {
+		if !clauseSatisfied(clause, assignment) {
+			return false
+		}
+	}
+	return true
+}
+
+// bruteForceMaxSAT returns the least cost of the soft clauses over the models of the hard ones
+// on variables 1..n, or -1 when there is none
+func bruteForceMaxSAT(hard CNF, soft []WeightedClause, n int) int {
+	best := -1
+	for mask := 0; mask < 1<<n; mask++ {
+		assignment := map[int]bool{}
+		for variable := 1; variable <= n; variable++ {
+			assignment[variable] = mask>>(variable-1)&1 == 1
+		}
+		if !hardSatisfied(hard, assignment) {
+			continue
+		}
+		if cost := softCost(soft, assignment); best < 0 || cost < best {
+			best = cost
+		}
+	}
+	return best
+}
+
+// randomMaxSAT returns three random hard ternary clauses and eight soft unit or binary clauses
+// over n variables
+func randomMaxSAT(rng *rand.Rand, n int) (CNF, []WeightedClause) {
+	literal := func() int {
+		variable := rng.Intn(n) + 1
+		if rng.Intn(2) == 0 {
+			return -variable
+		}
+		return variable
+	}
+	hard := CNF{}
+	for i := 0; i < 3; i++ {
+		hard = append(hard, Clause{literal(), literal(), literal()})
+	}
+	soft := []WeightedClause{}
+	for i := 0; i < 8; i++ {
+		clause := Clause{literal()}
+		if rng.Intn(2) == 0 {
+			clause = append(clause, literal())
+		}
+		soft = append(soft, WeightedClause{Weight: rng.Intn(4) + 1, Clause: clause})
+	}
+	return hard, soft
+}
+
+func TestMaxSATCoreGuided(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for round := 0; round < 200; round++ {
+		hard, soft := randomMaxSAT(rng, 5)
+		model, cost := MaxSATCoreGuided(hard, soft)
+		_, linear := MaxSATAnytime(hard, soft, func(int, map[int]bool) bool { return true })
+		if want := bruteForceMaxSAT(hard, soft, 5); cost != want || linear != want {
+			t.Fatalf("%v %v: core-guided cost %d, linear search %d, want %d", hard, soft, cost, linear, want)
+		}
+		if model != nil && (!hardSatisfied(hard, model) || softCost(soft, model) != cost) {
+			t.Fatalf("%v %v: the model %v does not have cost %d", hard, soft, model, cost)
+		}
+	}
+}
+
+func TestMaxSATAnytime(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for round := 0; round < 200; round++ {
+		hard, soft := randomMaxSAT(rng, 5)
+		bounds := []int{}
+		_, cost := MaxSATAnytime(hard, soft, func(satisfied int, model map[int]bool) bool {
+			bounds = append(bounds, satisfied)
+			return true
+		})
+		for i := 1; i < len(bounds); i++ {
+			if bounds[i] <= bounds[i-1] {
+				t.Fatalf("%v %v: the satisfied weights %v do not improve", hard, soft, bounds)
+			}
+		}
+		if want := bruteForceMaxSAT(hard, soft, 5); cost != want {
+			t.Fatalf("%v %v: got cost %d, want %d", hard, soft, cost, want)
+		}
+	}
+
+	hard, soft := CNF{}, []WeightedClause{{1, Clause{1}}, {1, Clause{-1}}, {2, Clause{2}}}
+	calls := 0
+	model, _ := MaxSATAnytime(hard, soft, func(int, map[int]bool) bool {
+		calls++
+		return false
+	})
+	if calls != 1 || model == nil {
+		t.Fatalf("stopping at the first model: %d calls, model %v", calls, model)
+	}
+}
+
+func TestUnsatisfiedClauses(t *testing.T) {
+	cnf := CNF{{1, 2}, {-1, 3}, {-2}, {3, 4}, {-1, -3}, {}}
+	assignment := map[int]bool{1: true, 2: true, 3: false} // 4 is unassigned
+	if got, want := UnsatisfiedClauses(cnf, assignment), []int{1, 2, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Models returns an iterator over the complete models of the CNF (one value per variable
+// of the formula). Each call solves for a model not returned before by adding a clause
+// blocking the previous one, and reports false once no models remain.
+func Models(cnf CNF) func() (map[int]bool, bool) {
+	variables := Variables(cnf)
+	blocked := append(CNF{}, cnf...)
+	done := false
+	return func() (map[int]bool, bool) {
+		if done {
+			return nil, false
+		}
+		assignment := make(map[int]bool)
+		if !DPLL(blocked, assignment) {
+			done = true
+			return nil, false
+		}
+		model := make(map[int]bool)
+		block := Clause{}
+		for _, variable := range variables {
+			value, exists := assignment[variable]
+			model[variable] = value || !exists // Unconstrained variables default to true
+			if model[variable] {
+				block = append(block, -variable)
+			} else {
+				block = append(block, variable)
+			}
+		}
+		blocked = append(blocked, block)
+		return model, true
+	}
+}
+
+// SolveAll passes every complete model of the CNF to cb, one at a time and never twice,
+// and stops early when cb returns false
+func SolveAll(cnf CNF, cb func(model map[int]bool) bool) {
+	next := Models(cnf)
+	for model, ok := next(); ok && cb(model); model, ok = next() {
+	}
+}
+
+// SATCertificate explains why the model satisfies the CNF: it lists the model, then each clause
+// with the literals that make it true, so the result can be checked by hand. It fails on the
+// first clause the model does not satisfy.
+func SATCertificate(cnf CNF, model map[int]bool) (string, error) {
+	var b strings.Builder
+	b.WriteString("model:")
+	for _, variable := range Variables(cnf) {
+		fmt.Fprintf(&b, " %d=%t", variable, model[variable])
+	}
+	b.WriteString("\n")
+	for i, clause := range cnf {
+		witnesses := []string{}
+		for _, literal := range clause {
+			if value, exists := model[abs(literal)]; exists && value == (literal > 0) {
+				witnesses = append(witnesses, strconv.Itoa(literal))
+			}
+		}
+		if len(witnesses) == 0 {
+			return "", fmt.Errorf("clause %d %s is not satisfied by the model", i+1, FormatCNF(CNF{clause}))
+		}
+		fmt.Fprintf(&b, "clause %d %s: satisfied by %s\n", i+1, FormatCNF(CNF{clause}), strings.Join(witnesses, ", "))
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestModelsLazy(t *testing.T) {
+	clause := Clause{}
+	for variable := 1; variable <= 40; variable++ {
+		clause = append(clause, variable)
+	}
+	cnf := CNF{clause} // 2^40 - 1 models, far too many to enumerate
+	next := Models(cnf)
+	first, ok1 := next()
+	second, ok2 := next()
+	if !ok1 || !ok2 {
+		t.Fatalf("got %v, %v, want two models", ok1, ok2)
+	}
+	if reflect.DeepEqual(first, second) {
+		t.Fatalf("the same model was returned twice: %v", first)
+	}
+	for _, model := range []map[int]bool{first, second} {
+		if len(model) != 40 || !clauseSatisfied(clause, model) {
+			t.Fatalf("got %v, want a complete model", model)
+		}
+	}
+
+	next = Models(CNF{{1, 2, 3}})
+	n := 0
+	for _, ok := next(); ok; _, ok = next() {
+		n++
+	}
+	if n != 7 {
+		t.Fatalf("got %d models of (1 OR 2 OR 3), want 7", n)
+	}
+	if _, ok := next(); ok {
+		t.Fatal("an exhausted iterator produced another model")
+	}
+}
+
+func TestSolveAllStopsEarly(t *testing.T) {
+	calls := 0
+	SolveAll(CNF{{1, 2, 3}}, func(map[int]bool) bool {
+		calls++
+		return calls < 2
+	})
+	if calls != 2 {
+		t.Fatalf("got %d callbacks, want 2", calls)
+	}
+
+	seen := make(map[string]bool)
+	SolveAll(CNF{{1, 2, 3}, {-1, -2}}, func(model map[int]bool) bool {
+		key := fmt.Sprint(model) // Maps print in key order
+		if seen[key] {
+			t.Fatalf("model %v was passed twice", model)
+		}
+		seen[key] = true
+		return true
+	})
+	if len(seen) != 5 {
+		t.Fatalf("got %d models, want 5", len(seen))
+	}
+}
+
+func TestSATCertificate(t *testing.T) {
+	cnf := CNF{{1, -2}, {2, 3}, {-1, -3, 2}}
+	certificate, err := SATCertificate(cnf, map[int]bool{1: true, 2: true, 3: false})
+	want := "model: 1=true 2=true 3=false\n" +
+		"clause 1 (1 OR -2): satisfied by 1\n" +
+		"clause 2 (2 OR 3): satisfied by 2\n" +
+		"clause 3 (-1 OR -3 OR 2): satisfied by -3, 2\n"
+	if err != nil || certificate != want {
+		t.Fatalf("got\n%s%v, want\n%s", certificate, err, want)
+	}
+	if _, err := SATCertificate(cnf, map[int]bool{1: false, 2: true, 3: true}); err == nil {
+		t.Fatal("a model falsifying clause 1 was certified")
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import "strings"
+
+// MUS returns the indices, in increasing order, of a minimal unsatisfiable subset of the clauses:
+// the subset is unsatisfiable but becomes satisfiable without any one of its clauses.
+// It returns nil when the CNF is satisfiable. Clauses are dropped one at a time and kept only
+// when the rest becomes satisfiable without them.
+func MUS(cnf CNF) []int {
+	if DPLL(cnf, make(map[int]bool)) {
+		return nil
+	}
+	core := []int{}
+	for i := range cnf {
+		core = append(core, i)
+	}
+	for k := 0; k < len(core); {
+		rest := CNF{}
+		for j, index := range core {
+			if j != k {
+				rest = append(rest, cnf[index])
+			}
+		}
+		if DPLL(rest, make(map[int]bool)) {
+			k++ // Clause core[k] is needed for the conflict
+		} else {
+			core = append(core[:k], core[k+1:]...)
+		}
+	}
+	return core
+}
+
+// ExplainUnsat describes why an unsatisfiable CNF has no model by naming the clauses of a minimal
+// unsatisfiable subset, such as "Clauses (1) and (-1) cannot both be satisfied."
+// It returns the empty string for a satisfiable CNF.
+func ExplainUnsat(cnf CNF) string {
+	core := MUS(cnf)
+	if core == nil {
+		return ""
+	}
+	names := []string{}
+	for _, index := range core {
+		names = append(names, FormatCNF(CNF{cnf[index]}))
+	}
+	switch len(names) {
+	case 1:
+		return "Clause " + names[0] + " can never be satisfied."
+	case 2:
+		return "Clauses " + names[0] + " and " + names[1] + " cannot both be satisfied."
+	}
+	return "Clauses " + strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1] + " cannot all be satisfied."
+}
+
+// IsRedundant reports whether the clause at clauseIndex is entailed by the other clauses, so that
+// removing it leaves the models unchanged: the others together with the negation of each of its
+// literals are unsatisfiable. An index out of range is never redundant.
+func IsRedundant(cnf CNF, clauseIndex int) bool {
+	if clauseIndex < 0 || clauseIndex >= len(cnf) {
+		return false
+	}
+	rest := CNF{}
+	for i, clause := range cnf {
+		if i != clauseIndex {
+			rest = append(rest, clause)
+		}
+	}
+	for _, literal := range cnf[clauseIndex] {
+		rest = append(rest, Clause{-literal})
+	}
+	sat, _ := Solve(rest)
+	return !sat
+}
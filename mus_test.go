@@ -0,0 +1,45 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExplainUnsat(t *testing.T) {
+	cnf := CNF{{1, 2}, {1}, {3}, {-1}}
+	if got := MUS(cnf); !reflect.DeepEqual(got, []int{1, 3}) {
+		t.Fatalf("MUS: got %v, want the clauses (1) and (-1)", got)
+	}
+	tests := []struct {
+		cnf  CNF
+		want string
+	}{
+		{cnf, "Clauses (1) and (-1) cannot both be satisfied."},
+		{CNF{{1, 2}, {-1}, {4}, {-2}}, "Clauses (1 OR 2), (-1) and (-2) cannot all be satisfied."},
+		{CNF{{1}, {}}, "Clause () can never be satisfied."},
+		{CNF{{1}, {2, -1}}, ""},
+	}
+	for _, test := range tests {
+		if got := ExplainUnsat(test.cnf); got != test.want {
+			t.Errorf("ExplainUnsat(%v): got %q, want %q", test.cnf, got, test.want)
+		}
+	}
+	if core := MUS(CNF{{1}}); core != nil {
+		t.Errorf("a satisfiable CNF has no core, got %v", core)
+	}
+}
+
+func TestIsRedundant(t *testing.T) {
+	cnf := CNF{{-1, 2}, {-2, 3}, {-1, 3}, {1, 4}}
+	if !IsRedundant(cnf, 2) {
+		t.Error("-1 | 3 follows from -1 | 2 and -2 | 3")
+	}
+	for _, index := range []int{0, 3, -1, 9} {
+		if IsRedundant(cnf, index) {
+			t.Errorf("clause %d was reported redundant", index)
+		}
+	}
+	if !IsRedundant(CNF{{1}, {1, 2}}, 1) || !IsRedundant(CNF{{1, -1}}, 0) || !IsRedundant(CNF{{LiteralTrue}, {2}}, 0) {
+		t.Error("subsumed clauses, tautologies and satisfied clauses are redundant")
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+// OccurrenceLists indexes the clauses of a formula by the literals they contain, and keeps the
+// index up to date as clauses are added and removed, so that inprocessing techniques can find the
+// clauses of a literal without scanning the whole formula. Clauses are identified by the id
+// returned when they are added; ids of the initial CNF are the clause indexes.
+type OccurrenceLists struct {
+	clauses []Clause      // Indexed by id, nil once removed
+	lists   map[int][]int // Literal to the ids of the clauses containing it
+}
+
+// NewOccurrenceLists builds the occurrence lists of the CNF
+func NewOccurrenceLists(cnf CNF) *OccurrenceLists {
+	o := &OccurrenceLists{lists: make(map[int][]int)}
+	for _, clause := range cnf {
+		o.Add(clause)
+	}
+	return o
+}
+
+// Add adds the clause and returns its id. A literal repeated in the clause is listed once.
+func (o *OccurrenceLists) Add(clause Clause) int {
+	id := len(o.clauses)
+	o.clauses = append(o.clauses, append(Clause{}, clause...))
+	for i, literal := range clause {
+		if !containsLiteral(clause[:i], literal) {
+			o.lists[literal] = append(o.lists[literal], id)
+		}
+	}
+	return id
+}
+
+// Remove removes the clause with the id, if it is still present
+func (o *OccurrenceLists) Remove(id int) {
+	if id < 0 || id >= len(o.clauses) || o.clauses[id] == nil {
+		return
+	}
+	for _, literal := range o.clauses[id] {
+		list := o.lists[literal]
+		for i, other := range list {
+			if other == id {
+				list[i] = list[len(list)-1]
+				list = list[:len(list)-1]
+				break
+			}
+		}
+		if len(list) == 0 {
+			delete(o.lists, literal)
+		} else {
+			o.lists[literal] = list
+		}
+	}
+	o.clauses[id] = nil
+}
+
+// Occurrences returns the ids of the clauses containing the literal, in no particular order.
+// The slice is shared with the index and must not be kept across Add or Remove.
+func (o *OccurrenceLists) Occurrences(literal int) []int {
+	return o.lists[literal]
+}
+
+// Clause returns the clause with the id, or nil when it was removed
+func (o *OccurrenceLists) Clause(id int) Clause {
+	if id < 0 || id >= len(o.clauses) {
+		return nil
+	}
+	return o.clauses[id]
+}
+
+// CNF returns the clauses present, in the order they were added
+func (o *OccurrenceLists) CNF() CNF {
+	cnf := CNF{}
+	for _, clause := range o.clauses {
+		if clause != nil {
+			cnf = append(cnf, clause)
+		}
+	}
+	return cnf
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestOccurrenceLists(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	cnf := CNF{{1, 2}, {-1, 3}, {2, 2, -3}}
+	o := NewOccurrenceLists(cnf)
+	present := map[int]Clause{0: cnf[0], 1: cnf[1], 2: cnf[2]}
+	for step := 0; step < 300; step++ {
+		if rng.Intn(2) == 0 || len(present) == 0 {
+			clause := Clause{}
+			for i := rng.Intn(3); i >= 0; i-- {
+				literal := 1 + rng.Intn(5)
+				if rng.Intn(2) == 0 {
+					literal = -literal
+				}
+				clause = append(clause, literal)
+			}
+			present[o.Add(clause)] = clause
+		} else {
+			for id := range present {
+				o.Remove(id)
+				o.Remove(id) // Removing twice is harmless
+				delete(present, id)
+				break
+			}
+		}
+		for literal := -5; literal <= 5; literal++ {
+			want := []int{}
+			for id, clause := range present {
+				if containsLiteral(clause, literal) {
+					want = append(want, id)
+				}
+			}
+			got := append([]int{}, o.Occurrences(literal)...)
+			sort.Ints(want)
+			sort.Ints(got)
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("step %d: occurrences of %d are %v, want %v", step, literal, got, want)
+			}
+		}
+		if got := len(o.CNF()); got != len(present) {
+			t.Fatalf("step %d: got %d clauses, want %d", step, got, len(present))
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+// EncodePBLeq encodes sum(coeffs[i] * lits[i]) <= bound, where a literal counts 1 when true,
+// into CNF using a BDD over the literals. Auxiliary variables are allocated by incrementing *nextVar.
+func EncodePBLeq(coeffs []int, lits []int, bound int, nextVar *int) CNF {
+	// Make every coefficient positive: c*l = c + |c|*(-l) when c < 0
+	var cs, ls []int
+	for i, c := range coeffs {
+		switch {
+		case c > 0:
+			cs, ls = append(cs, c), append(ls, lits[i])
+		case c < 0:
+			cs, ls = append(cs, -c), append(ls, -lits[i])
+			bound -= c
+		}
+	}
+	remaining := make([]int, len(cs)+1) // remaining[i] is the largest possible sum of terms i..
+	for i := len(cs) - 1; i >= 0; i-- {
+		remaining[i] = remaining[i+1] + cs[i]
+	}
+
+	const (
+		pbTrue  = 0  // The suffix always fits
+		pbFalse = -1 // The suffix can never fit
+	)
+	cnf := CNF{}
+	memo := make(map[[2]int]int)
+	var node func(i, k int) int
+	// node returns a variable implying that terms i.. sum to at most k
+	node = func(i, k int) int {
+		if k < 0 {
+			return pbFalse
+		}
+		if k >= remaining[i] {
+			return pbTrue
+		}
+		if v, exists := memo[[2]int{i, k}]; exists {
+			return v
+		}
+		hi, lo := node(i+1, k-cs[i]), node(i+1, k)
+		*nextVar++
+		v := *nextVar
+		memo[[2]int{i, k}] = v
+		switch hi { // When lits[i] is true the rest must fit in k - cs[i]
+		case pbFalse:
+			cnf = append(cnf, Clause{-v, -ls[i]})
+		case pbTrue:
+		default:
+			cnf = append(cnf, Clause{-v, -ls[i], hi})
+		}
+		switch lo { // Otherwise the rest must fit in k
+		case pbFalse:
+			cnf = append(cnf, Clause{-v, ls[i]})
+		case pbTrue:
+		default:
+			cnf = append(cnf, Clause{-v, ls[i], lo})
+		}
+		return v
+	}
+
+	switch root := node(0, bound); root {
+	case pbTrue:
+		return CNF{}
+	case pbFalse:
+		return CNF{Clause{}} // The empty clause: the constraint cannot hold
+	default:
+		return append(cnf, Clause{root})
+	}
+}
+
+// EncodePBGeq encodes sum(coeffs[i] * lits[i]) >= bound into CNF
+func EncodePBGeq(coeffs []int, lits []int, bound int, nextVar *int) CNF {
+	negated := make([]int, len(coeffs))
+	for i, c := range coeffs {
+		negated[i] = -c
+	}
+	return EncodePBLeq(negated, lits, -bound, nextVar)
+}
+
+// EncodePBEq encodes sum(coeffs[i] * lits[i]) == bound into CNF
+func EncodePBEq(coeffs []int, lits []int, bound int, nextVar *int) CNF {
+	return append(EncodePBLeq(coeffs, lits, bound, nextVar), EncodePBGeq(coeffs, lits, bound, nextVar)...)
+}
@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+// checkPBEncoding fixes every assignment of the constrained variables and checks that the encoding
+// is satisfiable exactly when the weighted sum of the true literals compares to the bound by holds
+func checkPBEncoding(t *testing.T, encode func([]int, []int, int, *int) CNF, holds func(sum, bound int) bool) {
+	t.Helper()
+	coeffs := []int{3, 2, 1, -2}
+	lits := []int{1, -2, 3, 4}
+	for bound := -4; bound <= 8; bound++ {
+		next := len(lits)
+		cnf := encode(coeffs, lits, bound, &next)
+		for mask := 0; mask < 1<<len(lits); mask++ {
+			fixed := append(CNF{}, cnf...)
+			sum := 0
+			for i, literal := range lits {
+				value := mask>>i&1 == 1
+				if value {
+					fixed = append(fixed, Clause{i + 1})
+				} else {
+					fixed = append(fixed, Clause{-(i + 1)})
+				}
+				if (literal > 0) == value {
+					sum += coeffs[i]
+				}
+			}
+			if DPLL(fixed, map[int]bool{}) != holds(sum, bound) {
+				t.Fatalf("bound %d, sum %d: the encoding disagrees with the constraint", bound, sum)
+			}
+		}
+	}
+}
+
+func TestEncodePB(t *testing.T) {
+	checkPBEncoding(t, EncodePBLeq, func(sum, bound int) bool { return sum <= bound })
+	checkPBEncoding(t, EncodePBGeq, func(sum, bound int) bool { return sum >= bound })
+	checkPBEncoding(t, EncodePBEq, func(sum, bound int) bool { return sum == bound })
+}
@@ -0,0 +1,234 @@
+package main
+
+import "sort"
+
+// Stage is one simplification in a Preprocess pipeline. Apply returns the simplified CNF and
+// records in the assignment any values it fixes; it reports that the formula is unsatisfiable
+// by returning a CNF that contains the empty clause.
+type Stage interface {
+	Apply(cnf CNF, assignment map[int]bool) CNF
+}
+
+// Preprocess runs the stages in order, over and over, until a whole round leaves the formula
+// unchanged. It returns the simplified CNF, which is equisatisfiable with the input, and the
+// values fixed on the way; together with a model of the simplified CNF they satisfy the input.
+func Preprocess(cnf CNF, stages []Stage) (CNF, map[int]bool) {
+	assignment := make(map[int]bool)
+	cnf = resolveConstants(cnf)
+	for {
+		before, fixed, variables := formulaSize(cnf), len(assignment), NumVariables(cnf)
+		for _, stage := range stages {
+			cnf = stage.Apply(cnf, assignment)
+			for _, clause := range cnf {
+				if len(clause) == 0 {
+					return CNF{Clause{}}, assignment
+				}
+			}
+		}
+		if formulaSize(cnf) == before && len(assignment) == fixed && NumVariables(cnf) == variables {
+			return cnf, assignment
+		}
+	}
+}
+
+// formulaSize returns the number of clauses and literals of the CNF as one measure of progress
+func formulaSize(cnf CNF) int {
+	n := len(cnf)
+	for _, clause := range cnf {
+		n += len(clause)
+	}
+	return n
+}
+
+// SimplifyStage drops tautologies and repeated literals, as Simplify does
+type SimplifyStage struct{}
+
+func (SimplifyStage) Apply(cnf CNF, assignment map[int]bool) CNF {
+	simplified, _ := Simplify(cnf, false)
+	return simplified
+}
+
+// UnitStage runs unit propagation
+type UnitStage struct{}
+
+func (UnitStage) Apply(cnf CNF, assignment map[int]bool) CNF {
+	simplified, ok := UnitPropagation(cnf, assignment)
+	if !ok {
+		return CNF{Clause{}}
+	}
+	return simplified
+}
+
+// PureLiteralStage assigns the literals that occur in only one polarity
+type PureLiteralStage struct{}
+
+func (PureLiteralStage) Apply(cnf CNF, assignment map[int]bool) CNF {
+	return PureLiteralElimination(cnf, assignment)
+}
+
+// SubsumptionStage drops every clause that contains all the literals of another clause,
+// keeping one copy of clauses that occur several times
+type SubsumptionStage struct{}
+
+func (SubsumptionStage) Apply(cnf CNF, assignment map[int]bool) CNF {
+	order := make([]int, len(cnf))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return len(cnf[order[i]]) < len(cnf[order[j]])
+	})
+	kept := []map[int]bool{}
+	subsumed := make([]bool, len(cnf))
+	for _, i := range order {
+		literals := make(map[int]bool, len(cnf[i]))
+		for _, literal := range cnf[i] {
+			literals[literal] = true
+		}
+		for _, shorter := range kept {
+			if subset(shorter, literals) {
+				subsumed[i] = true
+				break
+			}
+		}
+		if !subsumed[i] {
+			kept = append(kept, literals)
+		}
+	}
+	simplified := CNF{}
+	for i, clause := range cnf {
+		if !subsumed[i] {
+			simplified = append(simplified, clause)
+		}
+	}
+	return simplified
+}
+
+// subset reports whether every literal of a is in b
+func subset(a, b map[int]bool) bool {
+	for literal := range a {
+		if !b[literal] {
+			return false
+		}
+	}
+	return true
+}
+
+// FailedLiteralStage probes both literals of every variable: when assuming one leads to a
+// conflict by unit propagation, its negation is implied and is propagated
+type FailedLiteralStage struct{}
+
+func (FailedLiteralStage) Apply(cnf CNF, assignment map[int]bool) CNF {
+	for _, variable := range Variables(cnf) {
+		if _, exists := assignment[variable]; exists {
+			continue // Fixed while probing an earlier variable
+		}
+		for _, literal := range []int{variable, -variable} {
+			if !IsImpliedByUP(cnf, literal) {
+				continue
+			}
+			simplified, ok := UnitPropagation(append(CNF{Clause{literal}}, cnf...), assignment)
+			if !ok {
+				return CNF{Clause{}}
+			}
+			cnf = simplified
+			break
+		}
+	}
+	return cnf
+}
+
+// BVEStage eliminates variables by clause distribution: the clauses containing a variable are
+// replaced by all their non-tautological resolvents on it, when that does not add clauses.
+// The removed clauses are kept so that Reconstruct can extend a model of the result to the
+// eliminated variables; the same stage value must be used for both.
+type BVEStage struct {
+	eliminated []elimination
+}
+
+// elimination records a variable removed by BVEStage and the clauses in which it occurred positively
+type elimination struct {
+	variable int
+	clauses  CNF
+}
+
+func (b *BVEStage) Apply(cnf CNF, assignment map[int]bool) CNF {
+	occurrences := NewOccurrenceLists(cnf)
+	for _, variable := range Variables(cnf) {
+		positiveIDs, negativeIDs := occurrences.Occurrences(variable), occurrences.Occurrences(-variable)
+		if len(positiveIDs) == 0 || len(negativeIDs) == 0 {
+			continue // Pure or absent, which PureLiteralStage handles
+		}
+		ids := append(append([]int{}, positiveIDs...), negativeIDs...)
+		var positive, negative CNF
+		tautology := false
+		for _, id := range positiveIDs {
+			positive = append(positive, occurrences.Clause(id))
+		}
+		for _, id := range negativeIDs {
+			clause := occurrences.Clause(id)
+			tautology = tautology || containsLiteral(clause, variable)
+			negative = append(negative, clause)
+		}
+		if tautology {
+			continue // Left to SimplifyStage
+		}
+		resolvents := CNF{}
+		for _, p := range positive {
+			for _, n := range negative {
+				if resolvent, ok := resolve(p, n, variable); ok {
+					resolvents = append(resolvents, resolvent)
+				}
+			}
+			if len(resolvents) > len(positive)+len(negative) {
+				break
+			}
+		}
+		if len(resolvents) > len(positive)+len(negative) {
+			continue
+		}
+		b.eliminated = append(b.eliminated, elimination{variable: variable, clauses: positive})
+		for _, id := range ids {
+			occurrences.Remove(id)
+		}
+		for _, resolvent := range resolvents {
+			occurrences.Add(resolvent)
+		}
+	}
+	return occurrences.CNF()
+}
+
+// Reconstruct extends a model of the CNF left by the stage, in place, to the variables it
+// eliminated, undoing the eliminations in reverse. Variables missing from the model are set to
+// true, as CompleteAssignment does.
+func (b *BVEStage) Reconstruct(model map[int]bool) {
+	for i := len(b.eliminated) - 1; i >= 0; i-- {
+		e := b.eliminated[i]
+		model[e.variable] = false
+		for _, clause := range e.clauses {
+			CompleteAssignment(CNF{clause}, model)
+			if !clauseSatisfied(clause, model) {
+				model[e.variable] = true // Only this variable can satisfy the clause
+				break
+			}
+		}
+	}
+}
+
+// resolve returns the resolvent of p, which contains variable, and n, which contains its
+// negation, or reports false when the resolvent is a tautology
+func resolve(p, n Clause, variable int) (Clause, bool) {
+	seen := make(map[int]bool)
+	resolvent := Clause{}
+	for _, literal := range append(append(Clause{}, p...), n...) {
+		if abs(literal) == variable || seen[literal] {
+			continue
+		}
+		if seen[-literal] {
+			return nil, false
+		}
+		seen[literal] = true
+		resolvent = append(resolvent, literal)
+	}
+	return resolvent, true
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestPreprocessTwoStages(t *testing.T) {
+	// Propagating 1 leaves 5 pure; nothing else is fixed
+	cnf := CNF{{1}, {-1, 2, 3}, {-3, 4}, {-4, -2}, {5, -2}}
+	simplified, fixed := Preprocess(cnf, []Stage{UnitStage{}, PureLiteralStage{}})
+	if want := (CNF{{2, 3}, {-3, 4}, {-4, -2}}); !reflect.DeepEqual(simplified, want) {
+		t.Fatalf("got %v, want %v", simplified, want)
+	}
+	if want := map[int]bool{1: true, 5: true}; !reflect.DeepEqual(fixed, want) {
+		t.Fatalf("fixed: got %v, want %v", fixed, want)
+	}
+	if simplified, _ := Preprocess(CNF{{1}, {-1, 2}, {-2}}, []Stage{UnitStage{}, PureLiteralStage{}}); !reflect.DeepEqual(simplified, CNF{{}}) {
+		t.Fatalf("an unsatisfiable formula: got %v, want the empty clause", simplified)
+	}
+
+	rng := rand.New(rand.NewSource(3))
+	for round := 0; round < 300; round++ {
+		cnf := CNF{}
+		for i := 4 + rng.Intn(14); i > 0; i-- {
+			clause := Clause{}
+			for k := 1 + rng.Intn(3); k > 0; k-- {
+				literal := 1 + rng.Intn(6)
+				if rng.Intn(2) == 0 {
+					literal = -literal
+				}
+				clause = append(clause, literal)
+			}
+			cnf = append(cnf, clause)
+		}
+		want := DPLL(cnf, map[int]bool{})
+		for _, stages := range [][]Stage{
+			{UnitStage{}, PureLiteralStage{}},
+			{SimplifyStage{}, SubsumptionStage{}, FailedLiteralStage{}, UnitStage{}},
+		} {
+			simplified, fixed := Preprocess(cnf, stages)
+			model := map[int]bool{}
+			if got := DPLL(simplified, model); got != want {
+				t.Fatalf("%v: the simplified %v has answer %v, want %v", cnf, simplified, got, want)
+			}
+			if !want {
+				continue
+			}
+			for variable, value := range fixed {
+				model[variable] = value
+			}
+			if len(UnsatisfiedClauses(cnf, CompleteAssignment(cnf, model))) != 0 {
+				t.Fatalf("%v: the model %v with the fixed values %v falsifies a clause", cnf, model, fixed)
+			}
+		}
+	}
+}
+
+func TestBVEReconstruct(t *testing.T) {
+	// 3 only occurs in (1 OR 3) and (-3 OR 2), which BVE replaces by their resolvent (1 OR 2)
+	cnf := CNF{{1, 3}, {-3, 2}, {-1, -2, 4}, {-4, 5, 6}, {-5, -6}}
+	bve := &BVEStage{}
+	reduced, fixed := Preprocess(cnf, []Stage{bve})
+	for _, variable := range Variables(reduced) {
+		if variable == 3 {
+			t.Fatalf("3 was not eliminated from %v", reduced)
+		}
+	}
+	model := map[int]bool{}
+	if !DPLL(reduced, model) {
+		t.Fatal("the reduced formula is satisfiable")
+	}
+	model = CompleteAssignment(reduced, model)
+	for variable, value := range fixed {
+		model[variable] = value
+	}
+	bve.Reconstruct(model)
+	if _, exists := model[3]; !exists || len(UnsatisfiedClauses(cnf, model)) != 0 {
+		t.Fatalf("the reconstructed model %v does not satisfy %v", model, cnf)
+	}
+
+	rng := rand.New(rand.NewSource(9))
+	for round := 0; round < 500; round++ {
+		cnf := CNF{}
+		for i := 3 + rng.Intn(20); i > 0; i-- {
+			clause := Clause{}
+			for k := 1 + rng.Intn(4); k > 0; k-- {
+				literal := 1 + rng.Intn(7)
+				if rng.Intn(2) == 0 {
+					literal = -literal
+				}
+				clause = append(clause, literal)
+			}
+			cnf = append(cnf, clause)
+		}
+		want := DPLL(cnf, map[int]bool{})
+		got, model := Solve(cnf)
+		if got != want {
+			t.Fatalf("%v: Solve got %v, want %v", cnf, got, want)
+		}
+		if !got {
+			continue
+		}
+		if len(UnsatisfiedClauses(cnf, model)) != 0 {
+			t.Fatalf("%v: the model %v falsifies a clause", cnf, model)
+		}
+		for _, variable := range Variables(cnf) {
+			if _, exists := model[variable]; !exists {
+				t.Fatalf("%v: the model %v leaves %d out", cnf, model, variable)
+			}
+		}
+	}
+}
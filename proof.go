@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CheckProof verifies a DRAT proof of unsatisfiability of the CNF in the textual format of
+// drat-trim: each line is a lemma "l1 l2 ... 0" or a deletion "d l1 l2 ... 0", and lines starting
+// with "c" are comments. Every lemma must be a reverse unit propagation (RUP) consequence of the
+// clauses so far, which covers resolvents, or a resolution asymmetric tautology (RAT) on its first
+// literal. It reports true once the empty clause is derived, false when a lemma fails the check or
+// the proof ends without the empty clause, and an error when the proof cannot be read.
+func CheckProof(cnf CNF, proof io.Reader) (bool, error) {
+	clauses := append([]Clause{}, cnf...) // Deleted clauses become nil
+	scanner := bufio.NewScanner(proof)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] == "c" {
+			continue
+		}
+		deletion := fields[0] == "d"
+		if deletion {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 || fields[len(fields)-1] != "0" {
+			return false, fmt.Errorf("proof line %d: missing terminating 0", line)
+		}
+		lemma := Clause{}
+		for _, field := range fields[:len(fields)-1] {
+			literal, err := strconv.Atoi(field)
+			if err != nil || literal == 0 {
+				return false, fmt.Errorf("proof line %d: invalid literal %q", line, field)
+			}
+			lemma = append(lemma, literal)
+		}
+
+		if deletion {
+			key := clauseKey(lemma...)
+			for i, clause := range clauses {
+				if clause != nil && clauseKey(clause...) == key {
+					clauses[i] = nil
+					break
+				}
+			}
+			continue
+		}
+		if !implied(clauses, lemma) {
+			return false, nil
+		}
+		if len(lemma) == 0 {
+			return true, nil
+		}
+		clauses = append(clauses, lemma)
+	}
+	return false, scanner.Err()
+}
+
+// implied reports whether the lemma is RUP or RAT on its first literal with respect to the clauses
+func implied(clauses []Clause, lemma Clause) bool {
+	if rup(clauses, lemma) {
+		return true
+	}
+	if len(lemma) == 0 {
+		return false
+	}
+	pivot := lemma[0]
+	for _, clause := range clauses {
+		if clause == nil || !containsLiteral(clause, -pivot) {
+			continue
+		}
+		resolvent := append(Clause{}, lemma...)
+		for _, literal := range clause {
+			if literal != -pivot {
+				resolvent = append(resolvent, literal)
+			}
+		}
+		if !rup(clauses, resolvent) {
+			return false
+		}
+	}
+	return true
+}
+
+// rup reports whether unit propagation on the clauses with the negation of the lemma assumed
+// derives a conflict. A tautological lemma trivially does.
+func rup(clauses []Clause, lemma Clause) bool {
+	assignment := make(map[int]bool)
+	for _, literal := range lemma {
+		if value, exists := assignment[abs(literal)]; exists && value != (literal < 0) {
+			return true
+		}
+		assignment[abs(literal)] = literal < 0
+	}
+	return propagateAssignment(clauses, assignment)
+}
+
+// IsImpliedByUP reports whether the literal is implied by the CNF through unit propagation alone:
+// assuming its negation and propagating, without any branching, falsifies a clause
+func IsImpliedByUP(cnf CNF, literal int) bool {
+	return rup(resolveConstants(cnf), Clause{literal})
+}
+
+// propagateAssignment extends the assignment with the literals forced by unit clauses until a
+// fixpoint and reports whether some clause became falsified. Nil clauses are skipped.
+func propagateAssignment(clauses []Clause, assignment map[int]bool) bool {
+	for changed := true; changed; {
+		changed = false
+		for _, clause := range clauses {
+			if clause == nil || clauseSatisfied(clause, assignment) {
+				continue
+			}
+			free := 0
+			unassigned := 0
+			for _, literal := range clause {
+				if _, exists := assignment[abs(literal)]; !exists {
+					free++
+					unassigned = literal
+				}
+			}
+			if free == 0 {
+				return true
+			}
+			if free == 1 {
+				assignment[abs(unassigned)] = unassigned > 0
+				changed = true
+			}
+		}
+	}
+	return false
+}
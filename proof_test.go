@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckProof(t *testing.T) {
+	cnf := CNF{{1, 2}, {-1, 2}, {1, -2}, {-1, -2}}
+	tests := []struct {
+		proof string
+		want  bool
+	}{
+		{"c resolve on 1, then refute\n2 0\nd 1 2 0\n0\n", true},
+		{"1 0\n0\n", true},
+		{"3 0\n2 0\n0\n", true},       // (3) is RAT on the fresh variable 3
+		{"0\n", false},                // Tampered: the step deriving (2) was removed
+		{"-1 -2 0\n0\n", false},       // Tampered: (2) was replaced by a clause already present
+		{"d -1 2 0\n2 0\n0\n", false}, // (2) is no longer implied once (-1 OR 2) is deleted
+		{"2 0\n", false},              // No empty clause
+	}
+	for _, test := range tests {
+		if got, err := CheckProof(cnf, strings.NewReader(test.proof)); got != test.want || err != nil {
+			t.Errorf("CheckProof(%q): got %v, %v, want %v", test.proof, got, err, test.want)
+		}
+	}
+	for _, proof := range []string{"2 x 0\n", "2\n"} {
+		if _, err := CheckProof(cnf, strings.NewReader(proof)); err == nil {
+			t.Errorf("CheckProof(%q): got no error for a malformed line", proof)
+		}
+	}
+}
+
+func TestIsImpliedByUP(t *testing.T) {
+	chain := CNF{{1}, {-1, 2}, {-2, 3}, {4, 5}}
+	tests := []struct {
+		cnf     CNF
+		literal int
+		want    bool
+	}{
+		{chain, 3, true},
+		{chain, -3, false},
+		{chain, 4, false},
+		{CNF{{-1, 2}, {-1, -2}}, -1, true},
+		// 1 is implied, but only by case splitting on 2 and 3
+		{CNF{{1, 2, 3}, {1, -2, 3}, {1, 2, -3}, {1, -2, -3}}, 1, false},
+		{CNF{{LiteralFalse, 1}}, 1, true},
+	}
+	for _, test := range tests {
+		if got := IsImpliedByUP(test.cnf, test.literal); got != test.want {
+			t.Errorf("IsImpliedByUP(%v, %d): got %v, want %v", test.cnf, test.literal, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+// Quantifier is the kind of a quantifier block
+type Quantifier int
+
+const (
+	Exists Quantifier = iota
+	ForAll
+)
+
+// QuantifierBlock binds its variables with a single quantifier
+type QuantifierBlock struct {
+	Quantifier Quantifier
+	Variables  []int
+}
+
+// QBF is a prenex quantified boolean formula: a prefix of blocks, outermost first, over a CNF matrix.
+// Variables of the matrix that are not bound by the prefix are treated as outermost existentials.
+type QBF struct {
+	Prefix []QuantifierBlock
+	Matrix CNF
+}
+
+// qbfVariable records where a variable is bound
+type qbfVariable struct {
+	level     int // Index of the block, -1 for free variables
+	universal bool
+}
+
+// SolveQBF decides the QBF with a Q-DPLL search: it branches on variables in prefix order,
+// requires both branches of a universal variable and one branch of an existential variable,
+// and applies universal reduction and existential unit propagation at every node. Tautological
+// clauses are dropped first, since reducing one could leave a clause the adversary falsifies.
+func SolveQBF(q QBF) bool {
+	bound := make(map[int]qbfVariable)
+	order := []int{}
+	for _, variable := range Variables(q.Matrix) {
+		bound[variable] = qbfVariable{level: -1}
+	}
+	for level, block := range q.Prefix {
+		for _, variable := range block.Variables {
+			bound[variable] = qbfVariable{level: level, universal: block.Quantifier == ForAll}
+		}
+	}
+	for _, variable := range Variables(q.Matrix) { // Free variables come first
+		if bound[variable].level == -1 {
+			order = append(order, variable)
+		}
+	}
+	for _, block := range q.Prefix {
+		order = append(order, block.Variables...)
+	}
+	matrix, _ := Simplify(q.Matrix, false)
+	return qdpll(matrix, bound, order)
+}
+
+// qdpll decides the matrix under the prefix described by bound, branching in the given order
+func qdpll(cnf CNF, bound map[int]qbfVariable, order []int) bool {
+	for {
+		if len(cnf) == 0 {
+			return true
+		}
+		cnf = universalReduction(cnf, bound)
+		unit := 0
+		for _, clause := range cnf {
+			if len(clause) == 0 {
+				return false // Only universal literals were left, so the adversary falsifies the clause
+			}
+			if len(clause) == 1 && !bound[abs(clause[0])].universal {
+				unit = clause[0]
+			}
+		}
+		if unit == 0 {
+			break
+		}
+		cnf = assign(cnf, abs(unit), unit > 0)
+	}
+
+	// Branch on the outermost variable still present in the matrix
+	present := make(map[int]bool)
+	for _, variable := range Variables(cnf) {
+		present[variable] = true
+	}
+	for i, variable := range order {
+		if !present[variable] {
+			continue
+		}
+		rest := order[i+1:]
+		if bound[variable].universal {
+			return qdpll(assign(cnf, variable, true), bound, rest) && qdpll(assign(cnf, variable, false), bound, rest)
+		}
+		return qdpll(assign(cnf, variable, true), bound, rest) || qdpll(assign(cnf, variable, false), bound, rest)
+	}
+	return true
+}
+
+// universalReduction drops universal literals bound inside every existential literal of their clause
+func universalReduction(cnf CNF, bound map[int]qbfVariable) CNF {
+	reduced := make(CNF, len(cnf))
+	for i, clause := range cnf {
+		innermost := -2 // Deepest existential level in the clause
+		for _, literal := range clause {
+			if v := bound[abs(literal)]; !v.universal && v.level > innermost {
+				innermost = v.level
+			}
+		}
+		newClause := Clause{}
+		for _, literal := range clause {
+			if v := bound[abs(literal)]; !v.universal || v.level < innermost {
+				newClause = append(newClause, literal)
+			}
+		}
+		reduced[i] = newClause
+	}
+	return reduced
+}
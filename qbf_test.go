@@ -0,0 +1,82 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// bruteForceQBF expands every quantifier over the variables in order, which lists the free
+// variables first as existentials and then the prefix outermost first
+func bruteForceQBF(matrix CNF, order []int, universal map[int]bool, assignment map[int]bool) bool {
+	if len(order) == 0 {
+		for _, clause := range matrix {
+			satisfied := false
+			for _, literal := range clause {
+				satisfied = satisfied || assignment[abs(literal)] == (literal > 0)
+			}
+			if !satisfied {
+				return false
+			}
+		}
+		return true
+	}
+	variable := order[0]
+	assignment[variable] = true
+	whenTrue := bruteForceQBF(matrix, order[1:], universal, assignment)
+	assignment[variable] = false
+	whenFalse := bruteForceQBF(matrix, order[1:], universal, assignment)
+	delete(assignment, variable)
+	if universal[variable] {
+		return whenTrue && whenFalse
+	}
+	return whenTrue || whenFalse
+}
+
+func TestSolveQBFTautology(t *testing.T) {
+	q := QBF{
+		Prefix: []QuantifierBlock{{ForAll, []int{2}}, {ForAll, []int{3}}, {ForAll, []int{1}}},
+		Matrix: CNF{{-2, -3, 3}},
+	}
+	if !SolveQBF(q) {
+		t.Fatal("∀2∀3∀1.(-2 ∨ -3 ∨ 3) is true")
+	}
+}
+
+func TestSolveQBFBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for round := 0; round < 2000; round++ {
+		n := 1 + rng.Intn(5)
+		q := QBF{}
+		universal := make(map[int]bool)
+		var free, bound []int
+		for _, variable := range rng.Perm(n) {
+			variable++
+			if rng.Intn(5) == 0 {
+				free = append(free, variable)
+				continue
+			}
+			quantifier := Quantifier(rng.Intn(2))
+			if last := len(q.Prefix) - 1; last >= 0 && q.Prefix[last].Quantifier == quantifier && rng.Intn(2) == 0 {
+				q.Prefix[last].Variables = append(q.Prefix[last].Variables, variable)
+			} else {
+				q.Prefix = append(q.Prefix, QuantifierBlock{quantifier, []int{variable}})
+			}
+			universal[variable] = quantifier == ForAll
+			bound = append(bound, variable)
+		}
+		for m := rng.Intn(6); m > 0; m-- {
+			clause := Clause{}
+			for k := 1 + rng.Intn(4); k > 0; k-- { // Variables may repeat, giving tautologies
+				literal := 1 + rng.Intn(n)
+				if rng.Intn(2) == 0 {
+					literal = -literal
+				}
+				clause = append(clause, literal)
+			}
+			q.Matrix = append(q.Matrix, clause)
+		}
+		if got, want := SolveQBF(q), bruteForceQBF(q.Matrix, append(free, bound...), universal, map[int]bool{}); got != want {
+			t.Fatalf("%+v: got %v, want %v", q, got, want)
+		}
+	}
+}
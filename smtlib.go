@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// smtName returns the SMT-LIB constant used for a variable
+func smtName(variable int) string {
+	return fmt.Sprintf("x%d", variable)
+}
+
+// smtLiteral renders a literal as an SMT-LIB term
+func smtLiteral(literal int) string {
+	if literal < 0 {
+		return fmt.Sprintf("(not %s)", smtName(-literal))
+	}
+	return smtName(literal)
+}
+
+// WriteSMTLIB writes the CNF as an SMT-LIB 2 script that declares variables 1..numVars
+// (plus any larger variable used by the formula), asserts every clause and checks satisfiability
+func WriteSMTLIB(w io.Writer, cnf CNF, numVars int) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "(set-logic QF_UF)")
+	for variable := 1; variable <= numVars; variable++ {
+		fmt.Fprintf(bw, "(declare-const %s Bool)\n", smtName(variable))
+	}
+	for _, variable := range Variables(cnf) {
+		if variable > numVars {
+			fmt.Fprintf(bw, "(declare-const %s Bool)\n", smtName(variable))
+		}
+	}
+	for _, clause := range cnf {
+		switch len(clause) {
+		case 0: // The empty clause is false
+			fmt.Fprintln(bw, "(assert false)")
+		case 1:
+			fmt.Fprintf(bw, "(assert %s)\n", smtLiteral(clause[0]))
+		default:
+			terms := make([]string, len(clause))
+			for i, literal := range clause {
+				terms[i] = smtLiteral(literal)
+			}
+			fmt.Fprintf(bw, "(assert (or %s))\n", strings.Join(terms, " "))
+		}
+	}
+	fmt.Fprintln(bw, "(check-sat)")
+	return bw.Flush()
+}
+
+// sexpr is an SMT-LIB S-expression: an atom or a parenthesized list
+type sexpr struct {
+	atom   string
+	list   []sexpr
+	isList bool
+}
+
+// tokenizeSMTLIB splits SMT-LIB source into parentheses and atoms, dropping comments.
+// A quoted symbol or string literal that is never closed is an error.
+func tokenizeSMTLIB(src string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(src); {
+		switch c := src[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == ';': // Comment up to the end of the line
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '(' || c == ')':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '|' || c == '"': // Quoted symbol or string literal
+			end := strings.IndexByte(src[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("smtlib: unterminated %c at offset %d", c, i)
+			}
+			tokens = append(tokens, strings.Trim(src[i:i+end+2], "|"))
+			i += end + 2
+		default:
+			start := i
+			for i < len(src) && !strings.ContainsRune(" \t\n\r();", rune(src[i])) {
+				i++
+			}
+			tokens = append(tokens, src[start:i])
+		}
+	}
+	return tokens, nil
+}
+
+// parseSExprs builds the top-level S-expressions from the tokens
+func parseSExprs(tokens []string) ([]sexpr, error) {
+	var stack [][]sexpr
+	top := []sexpr{}
+	for _, token := range tokens {
+		switch token {
+		case "(":
+			stack = append(stack, top)
+			top = []sexpr{}
+		case ")":
+			if len(stack) == 0 {
+				return nil, ErrUnbalancedParens
+			}
+			list := sexpr{list: top, isList: true}
+			top = append(stack[len(stack)-1], list)
+			stack = stack[:len(stack)-1]
+		default:
+			top = append(top, sexpr{atom: token})
+		}
+	}
+	if len(stack) != 0 {
+		return nil, ErrUnbalancedParens
+	}
+	return top, nil
+}
+
+// ParseSMTLIBBool reads an SMT-LIB 2 script over Bool constants and converts its assertions to CNF.
+// Declared constants are numbered from 1 in declaration order and returned by name;
+// assertions that are not already clauses are encoded with Tseitin variables numbered after them.
+func ParseSMTLIBBool(r io.Reader) (CNF, map[string]int, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	tokens, err := tokenizeSMTLIB(string(src))
+	if err != nil {
+		return nil, nil, err
+	}
+	commands, err := parseSExprs(tokens)
+	if err != nil {
+		return nil, nil, err
+	}
+	vars := make(map[string]int)
+	var assertions []sexpr
+	for _, command := range commands {
+		if !command.isList || len(command.list) == 0 || command.list[0].isList {
+			return nil, nil, errors.New("smtlib: expected a command")
+		}
+		args := command.list[1:]
+		switch name := command.list[0].atom; name {
+		case "declare-const", "declare-fun":
+			if name == "declare-fun" {
+				if len(args) != 3 || !args[1].isList || len(args[1].list) != 0 {
+					return nil, nil, errors.New("smtlib: only declare-fun without arguments is supported")
+				}
+				args = []sexpr{args[0], args[2]}
+			}
+			if len(args) != 2 || args[0].isList {
+				return nil, nil, fmt.Errorf("smtlib: malformed %s", name)
+			}
+			if args[1].isList || args[1].atom != "Bool" {
+				return nil, nil, fmt.Errorf("smtlib: %s is not a Bool; only boolean problems are supported", args[0].atom)
+			}
+			if _, exists := vars[args[0].atom]; exists {
+				return nil, nil, fmt.Errorf("smtlib: %s is declared twice", args[0].atom)
+			}
+			vars[args[0].atom] = len(vars) + 1
+		case "assert":
+			if len(args) != 1 {
+				return nil, nil, errors.New("smtlib: assert takes exactly one term")
+			}
+			assertions = append(assertions, args[0])
+		case "set-logic", "set-info", "set-option", "check-sat", "get-model", "exit":
+			// Nothing to do
+		default:
+			return nil, nil, fmt.Errorf("smtlib: unsupported command %s", name)
+		}
+	}
+
+	cnf := CNF{}
+	next := len(vars)
+	for _, assertion := range assertions {
+		node, err := smtTerm(assertion, vars)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch node.Value {
+		case "TRUE":
+		case "FALSE":
+			cnf = append(cnf, Clause{})
+		default:
+			cnf = append(cnf, assertClauses(node, vars, &next)...)
+		}
+	}
+	return cnf, vars, nil
+}
+
+// smtTerm converts an SMT-LIB term built from and, or, not, true, false and declared constants
+// into a syntax tree, folding the constants away unless the whole term is one
+func smtTerm(term sexpr, vars map[string]int) (*Node, error) {
+	switch {
+	case !term.isList && term.atom == "true":
+		return &Node{Value: "TRUE"}, nil
+	case !term.isList && term.atom == "false":
+		return &Node{Value: "FALSE"}, nil
+	case !term.isList:
+		if _, exists := vars[term.atom]; !exists {
+			return nil, fmt.Errorf("smtlib: undeclared constant %s", term.atom)
+		}
+		return &Node{Value: term.atom}, nil
+	}
+	if len(term.list) < 2 || term.list[0].isList {
+		return nil, errors.New("smtlib: malformed term")
+	}
+	args := make([]*Node, len(term.list)-1)
+	for i, arg := range term.list[1:] {
+		node, err := smtTerm(arg, vars)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = node
+	}
+	switch op := term.list[0].atom; op {
+	case "not":
+		if len(args) != 1 {
+			return nil, errors.New("smtlib: not takes exactly one term")
+		}
+		switch args[0].Value {
+		case "TRUE":
+			return &Node{Value: "FALSE"}, nil
+		case "FALSE":
+			return &Node{Value: "TRUE"}, nil
+		}
+		return &Node{Value: "!", Left: args[0]}, nil
+	case "and", "or":
+		value, neutral, absorbing := "&", "TRUE", "FALSE"
+		if op == "or" {
+			value, neutral, absorbing = "|", "FALSE", "TRUE"
+		}
+		var node *Node
+		for _, arg := range args { // Constants are folded so that only the result can be one
+			switch {
+			case arg.Value == absorbing:
+				return arg, nil
+			case arg.Value == neutral:
+			case node == nil:
+				node = arg
+			default:
+				node = &Node{Value: value, Left: node, Right: arg}
+			}
+		}
+		if node == nil {
+			return &Node{Value: neutral}, nil
+		}
+		return node, nil
+	default:
+		return nil, fmt.Errorf("smtlib: unsupported operator %s; only and, or and not are supported", op)
+	}
+}
+
+// assertClauses returns clauses asserting the tree, copying conjunctions of clauses directly
+// and using the Tseitin encoding only for subformulas that are not clauses
+func assertClauses(node *Node, vars map[string]int, next *int) CNF {
+	if node.Value == "&" {
+		return append(assertClauses(node.Left, vars, next), assertClauses(node.Right, vars, next)...)
+	}
+	if clause, ok := nodeClause(node, vars); ok {
+		return CNF{clause}
+	}
+	cnf, root := tseitin(node, vars, next)
+	return append(cnf, Clause{root})
+}
+
+// nodeClause returns the literals of a tree that is a disjunction of variables and negated variables
+func nodeClause(node *Node, vars map[string]int) (Clause, bool) {
+	switch {
+	case node.Left == nil && node.Right == nil:
+		return Clause{vars[node.Value]}, true
+	case node.Value == "!" && node.Left.Left == nil && node.Left.Right == nil:
+		return Clause{-vars[node.Left.Value]}, true
+	case node.Value == "|":
+		left, ok := nodeClause(node.Left, vars)
+		if !ok {
+			return nil, false
+		}
+		right, ok := nodeClause(node.Right, vars)
+		return append(left, right...), ok
+	}
+	return nil, false
+}
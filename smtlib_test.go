@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteSMTLIB(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSMTLIB(&buf, CNF{{1, -2}, {3}, {-2, 4, -6}, {}}, 5); err != nil {
+		t.Fatal(err)
+	}
+	want := "(set-logic QF_UF)\n" +
+		"(declare-const x1 Bool)\n(declare-const x2 Bool)\n(declare-const x3 Bool)\n" +
+		"(declare-const x4 Bool)\n(declare-const x5 Bool)\n(declare-const x6 Bool)\n" +
+		"(assert (or x1 (not x2)))\n" +
+		"(assert x3)\n" +
+		"(assert (or (not x2) x4 (not x6)))\n" +
+		"(assert false)\n" +
+		"(check-sat)\n"
+	if buf.String() != want {
+		t.Fatalf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestParseSMTLIBBool(t *testing.T) {
+	src := `; A small boolean problem
+(set-logic QF_UF)
+(declare-const a Bool)
+(declare-fun |b c| () Bool)
+(declare-const d Bool)
+(assert (or a (not |b c|)))
+(assert (and (or (and a d) (not d)) |b c|))
+(assert (not (and a d)))
+(assert (or false true))
+(check-sat)`
+	cnf, vars, err := ParseSMTLIBBool(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assignment := map[int]bool{}
+	if !DPLL(cnf, assignment) || !assignment[vars["a"]] || !assignment[vars["b c"]] || assignment[vars["d"]] {
+		t.Fatalf("got %v over %v, want a and |b c| true and d false", assignment, vars)
+	}
+
+	if _, _, err := ParseSMTLIBBool(strings.NewReader("(declare-const x Int)")); err == nil {
+		t.Error("an Int constant was accepted")
+	}
+	if _, _, err := ParseSMTLIBBool(strings.NewReader("(declare-const x Bool)(assert (=> x x))")); err == nil {
+		t.Error("an unsupported operator was accepted")
+	}
+	for _, src := range []string{"(assert |abc", `(set-info :source "abc)`} {
+		if _, _, err := ParseSMTLIBBool(strings.NewReader(src)); err == nil {
+			t.Errorf("%q: an unterminated quote was accepted", src)
+		}
+	}
+
+	var buf bytes.Buffer // The exporter writes the empty clause as false
+	WriteSMTLIB(&buf, CNF{{1}, {}}, 1)
+	if cnf, _, err := ParseSMTLIBBool(&buf); err != nil || DPLL(cnf, map[int]bool{}) {
+		t.Errorf("got %v, %v, want an unsatisfiable formula", cnf, err)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+// Solve decides the CNF and returns a model when it is satisfiable. The formula is first
+// preprocessed by dropping tautologies, unit propagation and variable elimination; then the
+// procedure is chosen by its shape: Solve2SAT when every clause has at most two literals,
+// SolveHorn for Horn formulas and DPLL otherwise. The model is extended back over every variable
+// of the input, undoing the eliminations. The CNF is never modified and the model is a fresh
+// map, so the same formula can be solved repeatedly, always with the same model.
+func Solve(cnf CNF) (bool, map[int]bool) {
+	cnf = resolveConstants(cnf)
+	bve := &BVEStage{}
+	reduced, fixed := Preprocess(cnf, []Stage{SimplifyStage{}, UnitStage{}, bve})
+	sat, model := solveShape(reduced)
+	if !sat {
+		return false, nil
+	}
+	for variable, value := range fixed {
+		model[variable] = value
+	}
+	bve.Reconstruct(model)
+	return true, CompleteAssignment(cnf, model)
+}
+
+// solveShape is Solve without preprocessing
+func solveShape(cnf CNF) (bool, map[int]bool) {
+	binary := true
+	for _, clause := range cnf {
+		if len(clause) > 2 {
+			binary = false
+			break
+		}
+	}
+	if binary {
+		return Solve2SAT(cnf, 0)
+	}
+	if IsHorn(cnf) {
+		return SolveHorn(cnf)
+	}
+	return solveModel(cnf)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestSolveDoesNotMutate(t *testing.T) {
+	for _, cnf := range []CNF{
+		{{1, 2, 3}, {-1, 2}, {-2, 3, 1}, {-3}, {4, -4, 5}, {2, 2}},
+		{{1, 2}, {-1}, {-2}},
+		{{-1, -2}, {1}, {LiteralTrue, 3}},
+	} {
+		// Give every clause spare capacity filled with a sentinel, so appends to a caller's
+		// backing array show up as well as changes within the clauses
+		for i, clause := range cnf {
+			backing := make(Clause, len(clause), len(clause)+4)
+			copy(backing, clause)
+			for j := len(clause); j < cap(backing); j++ {
+				backing[:cap(backing)][j] = 99
+			}
+			cnf[i] = backing
+		}
+		dump := func() string {
+			full := CNF{}
+			for _, clause := range cnf {
+				full = append(full, clause[:cap(clause)])
+			}
+			return fmt.Sprint(full)
+		}
+		before := dump()
+		first, firstModel := Solve(cnf)
+		second, secondModel := Solve(cnf)
+		DPLL(cnf, map[int]bool{})
+		if after := dump(); after != before {
+			t.Fatalf("the input changed from\n%s to\n%s", before, after)
+		}
+		if first != second || !reflect.DeepEqual(firstModel, secondModel) {
+			t.Fatalf("%v: solving twice gave %v %v and %v %v", cnf, first, firstModel, second, secondModel)
+		}
+	}
+}
+
+func TestZeroVariables(t *testing.T) {
+	for _, c := range []struct {
+		cnf         CNF
+		satisfiable bool
+		models      int64
+	}{
+		{nil, true, 1},
+		{CNF{}, true, 1},
+		{CNF{{LiteralTrue}}, true, 1},
+		{CNF{{}}, false, 0},
+		{CNF{{}, {}}, false, 0},
+		{CNF{{LiteralFalse}}, false, 0},
+	} {
+		satisfiable, model := Solve(c.cnf)
+		if satisfiable != c.satisfiable || satisfiable && (model == nil || len(model) != 0) || !satisfiable && model != nil {
+			t.Errorf("Solve(%v): got %v, %v", c.cnf, satisfiable, model)
+		}
+		if got := CountModels(c.cnf, 0); got.Int64() != c.models {
+			t.Errorf("CountModels(%v): got %v, want %d", c.cnf, got, c.models)
+		}
+		if got := CountModelsParallel(c.cnf, 0, 3); got.Int64() != c.models {
+			t.Errorf("CountModelsParallel(%v): got %v, want %d", c.cnf, got, c.models)
+		}
+		if got := CompleteAssignment(c.cnf, map[int]bool{}); got == nil || len(got) != 0 {
+			t.Errorf("CompleteAssignment(%v): got %v, want an empty model", c.cnf, got)
+		}
+		if got, _, _ := SolveLookahead(c.cnf); got != c.satisfiable {
+			t.Errorf("SolveLookahead(%v): got %v", c.cnf, got)
+		}
+		if got, _ := Solve2SAT(c.cnf, 0); got != c.satisfiable {
+			t.Errorf("Solve2SAT(%v): got %v", c.cnf, got)
+		}
+		if got, _ := SolveHorn(c.cnf); got != c.satisfiable {
+			t.Errorf("SolveHorn(%v): got %v", c.cnf, got)
+		}
+	}
+	if CompleteAssignment(CNF{}, nil) == nil {
+		t.Error("CompleteAssignment must return a model for a nil assignment")
+	}
+}
+
+func TestDeterministicSolve(t *testing.T) {
+	for seed := int64(0); seed < 40; seed++ {
+		cnf := append(random3SAT(25, 60+int(seed), seed), Clause{1, 2}, Clause{-3, 4}, Clause{5})
+		satisfiable, model := Solve(cnf)
+		assignment := map[int]bool{}
+		dpllSatisfiable := DPLL(cnf, assignment)
+		for i := 0; i < 20; i++ {
+			if again, againModel := Solve(cnf); again != satisfiable || !reflect.DeepEqual(againModel, model) {
+				t.Fatalf("seed %d, run %d: Solve returned %v, %v, then %v, %v", seed, i, satisfiable, model, again, againModel)
+			}
+			againAssignment := map[int]bool{}
+			if again := DPLL(cnf, againAssignment); again != dpllSatisfiable || !reflect.DeepEqual(againAssignment, assignment) {
+				t.Fatalf("seed %d, run %d: DPLL returned %v, then %v", seed, i, assignment, againAssignment)
+			}
+		}
+	}
+}
@@ -0,0 +1,1226 @@
+package main
+
+import (
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Stats counts the work done by a Solver
+type Stats struct {
+	Decisions    int         // Branching decisions made
+	Conflicts    int         // Clauses found falsified
+	Propagations int         // Literals assigned by unit propagation
+	ProofReason  ProofReason // How unsatisfiability was established
+	Reductions   int         // Reductions of the clauses learned with WithConflictAnalyzer
+	Deleted      int         // Learned clauses deleted by the reductions
+	Unknown      int         // Instances given up on at the timeout, counted by CompareHeuristics
+
+	// Wall-clock time, accumulated over resumed solves
+	PropagationTime time.Duration // Spent in unit propagation
+	ConflictTime    time.Duration // Spent handling conflicts: proof output and backtracking
+	DecisionTime    time.Duration // Spent choosing decision literals
+	SolveTime       time.Duration // Spent in Solve overall
+}
+
+// ProofReason records the mechanism that closed the search of an unsatisfiable formula.
+// Pure literal reasoning only removes clauses, so it never closes the search on its own.
+type ProofReason int
+
+const (
+	ProofNone        ProofReason = iota // Satisfiable, or not solved yet
+	ProofEmptyClause                    // The formula contains an empty clause
+	ProofPropagation                    // Unit propagation failed before any decision
+	ProofSearch                         // Every branch of the search failed
+)
+
+func (r ProofReason) String() string {
+	names := [...]string{"none", "empty clause", "unit propagation", "search"}
+	if r < 0 || int(r) >= len(names) {
+		return fmt.Sprintf("ProofReason(%d)", int(r))
+	}
+	return names[r]
+}
+
+// statsJSON is the JSON form of Stats: durations are in nanoseconds and the proof reason is its name
+type statsJSON struct {
+	Decisions         int    `json:"decisions"`
+	Conflicts         int    `json:"conflicts"`
+	Propagations      int    `json:"propagations"`
+	ProofReason       string `json:"proof_reason"`
+	Unknown           int    `json:"unknown"`
+	Reductions        int    `json:"reductions"`
+	Deleted           int    `json:"deleted"`
+	PropagationTimeNs int64  `json:"propagation_time_ns"`
+	ConflictTimeNs    int64  `json:"conflict_time_ns"`
+	DecisionTimeNs    int64  `json:"decision_time_ns"`
+	SolveTimeNs       int64  `json:"solve_time_ns"`
+}
+
+// MarshalJSON encodes the statistics for logging and external tools
+func (st Stats) MarshalJSON() ([]byte, error) {
+	return json.Marshal(statsJSON{
+		Decisions:         st.Decisions,
+		Conflicts:         st.Conflicts,
+		Propagations:      st.Propagations,
+		ProofReason:       st.ProofReason.String(),
+		Unknown:           st.Unknown,
+		Reductions:        st.Reductions,
+		Deleted:           st.Deleted,
+		PropagationTimeNs: int64(st.PropagationTime),
+		ConflictTimeNs:    int64(st.ConflictTime),
+		DecisionTimeNs:    int64(st.DecisionTime),
+		SolveTimeNs:       int64(st.SolveTime),
+	})
+}
+
+// UnmarshalJSON decodes statistics written by MarshalJSON
+func (st *Stats) UnmarshalJSON(data []byte) error {
+	var j statsJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	reason, found := ProofNone, j.ProofReason == ""
+	for r := ProofNone; r <= ProofSearch; r++ {
+		if r.String() == j.ProofReason {
+			reason, found = r, true
+		}
+	}
+	if !found {
+		return fmt.Errorf("stats: unknown proof reason %q", j.ProofReason)
+	}
+	*st = Stats{
+		Decisions:       j.Decisions,
+		Conflicts:       j.Conflicts,
+		Propagations:    j.Propagations,
+		ProofReason:     reason,
+		Unknown:         j.Unknown,
+		Reductions:      j.Reductions,
+		Deleted:         j.Deleted,
+		PropagationTime: time.Duration(j.PropagationTimeNs),
+		ConflictTime:    time.Duration(j.ConflictTimeNs),
+		DecisionTime:    time.Duration(j.DecisionTimeNs),
+		SolveTime:       time.Duration(j.SolveTimeNs),
+	}
+	return nil
+}
+
+// Option configures a Solver
+type Option func(*Solver)
+
+// ClauseCheck selects what AddClause does with a clause that repeats a variable, either as the
+// same literal or as a tautology containing a literal and its negation
+type ClauseCheck int
+
+const (
+	ClauseKeep      ClauseCheck = iota // Add the clause as given
+	ClauseNormalize                    // Drop repeated literals, and skip tautologies altogether
+	ClauseReject                       // Return an error and leave the clause out
+)
+
+// WithClauseCheck sets how AddClause treats clauses that repeat a variable; the default is ClauseKeep
+func WithClauseCheck(check ClauseCheck) Option {
+	return func(s *Solver) {
+		s.clauseCheck = check
+	}
+}
+
+// WithProgress calls cb with the current statistics roughly every interval while solving.
+// The callback runs on the solving goroutine, so it should return quickly.
+func WithProgress(interval time.Duration, cb func(Stats)) Option {
+	return func(s *Solver) {
+		s.progressInterval = interval
+		s.progress = cb
+	}
+}
+
+// Polarity selects the value tried first for a decision variable
+type Polarity int
+
+const (
+	PolarityTrue       Polarity = iota // Always try true first
+	PolarityFalse                      // Always try false first, which suits many industrial formulas
+	PolarityOccurrence                 // Try the literal that occurs in more clauses first
+)
+
+// WithDefaultPolarity sets the polarity strategy used for decisions
+func WithDefaultPolarity(strategy Polarity) Option {
+	return func(s *Solver) {
+		s.polarity = strategy
+	}
+}
+
+// WithVariableOrder makes decisions prefer the given variables in order, falling back to the
+// default choice once none of them is left unassigned in an unsatisfied clause
+func WithVariableOrder(order []int) Option {
+	return func(s *Solver) {
+		s.order = append([]int{}, order...)
+	}
+}
+
+// WithLRATProof writes an LRAT proof of unsatisfiability to w while solving. Whenever a branch
+// fails, the negation of the decisions still to be flipped is added as a lemma whose hints are
+// the clauses that propagated along the trail and the falsified clause; the lemma then serves as
+// the reason for the flipped decision. Original clauses have ids 1, 2, ... in the order they were
+// added. The proof covers a single solve without assumptions and is not carried over by SaveState.
+func WithLRATProof(w io.Writer) Option {
+	return func(s *Solver) {
+		s.proof = w
+	}
+}
+
+// WithLearnedClauseHook calls hook once per conflict with a copy of the clause learned from it
+// and lbd, the number of decision levels among its literals. The hook runs on the solving goroutine.
+func WithLearnedClauseHook(hook func(clause Clause, lbd int)) Option {
+	return func(s *Solver) {
+		s.learnedHook = hook
+	}
+}
+
+// Decider is a branching heuristic. Decide receives the clauses not yet satisfied, restricted to
+// their unassigned literals, and returns the variable to branch on; the polarity is chosen by the
+// Solver. There is always at least one clause and each has at least one literal.
+type Decider interface {
+	Decide(clauses []Clause) int
+}
+
+// WithDecider makes decisions use the heuristic instead of the default choice and WithVariableOrder
+func WithDecider(d Decider) Option {
+	return func(s *Solver) {
+		s.decider = d
+	}
+}
+
+// WithShortestClauseBranching branches on the variable of the literal occurring most often among the
+// shortest clauses not yet satisfied
+func WithShortestClauseBranching() Option {
+	return WithDecider(shortestClauseDecider{})
+}
+
+// WithTieBreaking makes the built-in scoring heuristics prefer variable x over y when their scores
+// are equal and less(x, y) holds. less must be a strict total order for runs to be reproducible.
+// Without it the lowest variable wins.
+func WithTieBreaking(less func(x, y int) bool) Option {
+	return func(s *Solver) {
+		s.less = less
+	}
+}
+
+// scorer is a Decider that scores candidate variables and leaves the choice among the best,
+// including the tie-breaking, to the Solver
+type scorer interface {
+	Decider
+	scores(clauses []Clause) map[int]int
+}
+
+// bestVariable returns the variable with the highest score, breaking ties with less,
+// or the lowest variable when less is nil
+func bestVariable(scores map[int]int, less func(x, y int) bool) int {
+	if less == nil {
+		less = func(x, y int) bool { return x < y }
+	}
+	best := 0
+	for variable, score := range scores {
+		if best == 0 || score > scores[best] || score == scores[best] && less(variable, best) {
+			best = variable
+		}
+	}
+	return best
+}
+
+// shortestCounts returns the number of occurrences of each literal in the shortest clauses
+func shortestCounts(clauses []Clause) map[int]int {
+	shortest := len(clauses[0])
+	for _, clause := range clauses {
+		if len(clause) < shortest {
+			shortest = len(clause)
+		}
+	}
+	counts := make(map[int]int)
+	for _, clause := range clauses {
+		if len(clause) == shortest {
+			for _, literal := range clause {
+				counts[literal]++
+			}
+		}
+	}
+	return counts
+}
+
+// shortestClauseDecider implements WithShortestClauseBranching
+type shortestClauseDecider struct{}
+
+func (d shortestClauseDecider) Decide(clauses []Clause) int {
+	return bestVariable(d.scores(clauses), nil)
+}
+
+// scores gives each variable the count of its more frequent literal in the shortest clauses
+func (shortestClauseDecider) scores(clauses []Clause) map[int]int {
+	scores := make(map[int]int)
+	for literal, count := range shortestCounts(clauses) {
+		if count > scores[abs(literal)] {
+			scores[abs(literal)] = count
+		}
+	}
+	return scores
+}
+
+// MOMDecider is the MOM heuristic (Maximum Occurrences in clauses of Minimum size): over the
+// shortest clauses it picks the variable x maximizing (f(x)+f(-x))*2^K + f(x)*f(-x), where f counts
+// occurrences of a literal, so frequent variables win and balanced ones break the ties
+type MOMDecider struct {
+	K int // Weight exponent of the sum; 4 if zero
+}
+
+func (m MOMDecider) Decide(clauses []Clause) int {
+	return bestVariable(m.scores(clauses), nil)
+}
+
+func (m MOMDecider) scores(clauses []Clause) map[int]int {
+	return momScores(clauses, m.K)
+}
+
+// momScores returns the MOM score of every variable of the shortest clauses
+func momScores(clauses []Clause, k int) map[int]int {
+	if k == 0 {
+		k = 4
+	}
+	counts := shortestCounts(clauses)
+	scores := make(map[int]int)
+	for literal := range counts {
+		variable := abs(literal)
+		positive, negative := counts[variable], counts[-variable]
+		scores[variable] = (positive+negative)<<uint(k) + positive*negative
+	}
+	return scores
+}
+
+// WithConflictAnalyzer replaces chronological backtracking by clause learning: on every conflict
+// the analyzer derives a clause, which is kept until the next solve, and the search jumps back
+// to the level it returns, or by one level with WithChronologicalBacktracking. Proof output from
+// WithLRATProof is not written in this mode. A nil analyzer keeps chronological backtracking.
+// Once the learned clauses reach a limit, a third of the input clauses but at least minLemmaLimit,
+// the less active half of them is deleted and the limit grows by lemmaLimitGrowth. A clause is
+// active when it took part in recent conflicts, as the falsified clause or as a reason.
+func WithConflictAnalyzer(a ConflictAnalyzer) Option {
+	return func(s *Solver) {
+		s.analyzer = a
+	}
+}
+
+// WithClauseLearning is WithConflictAnalyzer with the default FirstUIP analysis
+func WithClauseLearning() Option {
+	return WithConflictAnalyzer(FirstUIP{})
+}
+
+// WithChronologicalBacktracking makes the search with WithConflictAnalyzer undo only the latest
+// decision level after a conflict instead of jumping back to the analyzer's level. The learned
+// clause is kept and becomes unit there, so its literal is propagated at that level. Without an
+// analyzer the search backtracks chronologically anyway.
+func WithChronologicalBacktracking(enabled bool) Option {
+	return func(s *Solver) {
+		s.chronological = enabled
+	}
+}
+
+const (
+	lemmaActivityDecay = 0.999 // Factor by which the activity of a lemma decays at every conflict
+	minLemmaLimit      = 100   // Lemmas kept before the first reduction, for small formulas
+	lemmaLimitGrowth   = 1.1   // Growth of the number of lemmas kept after each reduction
+)
+
+// WithMaxLearnedClauses caps the clauses kept by WithConflictAnalyzer at n, at least 1. Before a
+// learned clause would exceed the cap the least active ones are deleted, and when too many of them
+// are the reasons of assignments the search restarts from level 0 to free them; values forced at
+// level 0 by a deleted clause stay, but no longer have a Reason.
+func WithMaxLearnedClauses(n int) Option {
+	return func(s *Solver) {
+		s.maxLearned = max(n, 1)
+	}
+}
+
+// defaultCompactionThreshold is the number of deleted lemmas that triggers a compaction by default
+const defaultCompactionThreshold = 64
+
+// WithCompactionThreshold makes the solver compact its learned clauses once n of them have been
+// deleted, dropping them along with those satisfied before any decision and renumbering the
+// rest. Deleted lemmas are skipped until then. The default is defaultCompactionThreshold.
+func WithCompactionThreshold(n int) Option {
+	return func(s *Solver) {
+		s.compactAfter = max(n, 1)
+	}
+}
+
+// Solver is an iterative DPLL solver that keeps its assignments on an explicit trail
+// and backtracks chronologically, flipping the most recent untried decision, unless
+// WithConflictAnalyzer makes it learn clauses and backjump.
+type Solver struct {
+	clauses  []Clause
+	numVars  int
+	values   []int8 // Per variable: 1 true, -1 false, 0 unassigned
+	level    []int  // Per variable: decision level of its assignment
+	trail    []int  // Assigned literals in order
+	trailLim []int  // Trail index where each decision level starts
+	flipped  []bool // Per decision level: whether the decision was already negated
+	reason   []int  // Per variable: id of the clause that implied its value, 0 for a decision
+	conflict int    // Id of the clause falsified by the last failed propagation
+	stats    Stats
+	paused   bool // The last solve was cancelled and will be resumed
+
+	polarity    Polarity
+	occurrences map[int]int  // Literal occurrence counts for PolarityOccurrence
+	order       []int        // Preferred decision variables
+	phases      map[int]bool // Saved phases from WarmStart, preferred over the polarity strategy
+	decider     Decider
+	less        func(x, y int) bool // Tie-breaking for scoring deciders
+	clauseCheck ClauseCheck
+
+	assumptions []int // Literals assumed true by the current SolveUnder
+	failed      []int // Minimal failed assumptions of the last SolveUnder
+
+	proof       io.Writer
+	learnedHook func(Clause, int)
+	nextID      int // Id of the next lemma written to the proof
+	learned     int // Id of the last lemma, the reason for the next flipped decision
+
+	analyzer      ConflictAnalyzer
+	chronological bool      // Undo one level after a learned clause rather than backjump
+	lemmas        []Clause  // Clauses learned during the current solve; nil once deleted
+	activity      []float64 // Per lemma, bumped whenever it takes part in a conflict
+	activityInc   float64   // Current bump, grown after every conflict to decay older bumps
+	maxLemmas     float64   // Lemmas kept before the less active half is deleted
+	maxLearned    int       // Hard cap on the lemmas kept, 0 for none
+	deleted       int       // Lemmas deleted since the last compaction
+	compactAfter  int       // Deleted lemmas that trigger a compaction
+
+	progressInterval time.Duration
+	progress         func(Stats)
+	lastProgress     time.Time
+}
+
+// NewSolver returns an empty solver configured by the options
+func NewSolver(opts ...Option) *Solver {
+	s := &Solver{values: make([]int8, 1), level: make([]int, 1), reason: make([]int, 1), compactAfter: defaultCompactionThreshold}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// AddClause adds a clause to the formula. A clause containing LiteralTrue is skipped and
+// LiteralFalse literals are dropped. A clause repeating a variable is handled as set by
+// WithClauseCheck; with ClauseReject it is not added and the error is a *ValidationError.
+// Adding a clause after a cancelled solve discards its search, so the next solve starts over.
+func (s *Solver) AddClause(clause Clause) error {
+	resolved := resolveConstants(CNF{clause})
+	if len(resolved) == 0 {
+		return nil
+	}
+	clause = resolved[0]
+	if s.clauseCheck != ClauseKeep {
+		simplified, warnings := Simplify(CNF{clause}, true)
+		if len(warnings) > 0 && s.clauseCheck == ClauseReject {
+			reason := "repeated literal"
+			if len(simplified) == 0 {
+				reason = "contains a literal and its negation"
+			}
+			return &ValidationError{ClauseIndex: len(s.clauses), Reason: reason}
+		}
+		if len(simplified) == 0 {
+			return nil // Tautology
+		}
+		clause = simplified[0]
+	}
+	for _, literal := range clause {
+		s.grow(abs(literal))
+	}
+	s.clauses = append(s.clauses, append(Clause{}, clause...))
+	s.paused = false // Lemma ids follow the clauses, so the saved search no longer matches them
+	return nil
+}
+
+// NewVar allocates a variable not used by any clause yet and returns it, growing the solver's
+// per-variable state, so that encoders can introduce auxiliary variables between solves
+func (s *Solver) NewVar() int {
+	s.grow(s.numVars + 1)
+	return s.numVars
+}
+
+// grow makes room for the variables up to the given one
+func (s *Solver) grow(variable int) {
+	for variable > s.numVars {
+		s.numVars++
+		s.values = append(s.values, 0)
+		s.level = append(s.level, 0)
+		s.reason = append(s.reason, 0)
+	}
+}
+
+// AddClauses adds every clause received from the channel until it is closed, so that an encoder
+// running in another goroutine can stream clauses into the solver without building a CNF first.
+// A clause AddClause rejects is skipped; the channel is still drained so the sender never blocks,
+// and the first such error is returned.
+func (s *Solver) AddClauses(clauses <-chan Clause) error {
+	var first error
+	for clause := range clauses {
+		if err := s.AddClause(clause); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Propagate extends the partial assignment in place with every literal forced by unit propagation
+// over the solver's clauses and reports whether a clause became falsified. It does not touch the
+// search state, so it can drive probing or lookahead between solves.
+func (s *Solver) Propagate(assignment map[int]bool) (conflict bool) {
+	return propagateAssignment(s.clauses, assignment)
+}
+
+// Stats returns the statistics of the last solve
+func (s *Solver) Stats() Stats {
+	return s.stats
+}
+
+// WarmStart seeds the saved phases with a model, typically of a closely related formula, so that
+// decisions try its values first. It is only a hint: the search still explores both values.
+func (s *Solver) WarmStart(model map[int]bool) {
+	s.phases = make(map[int]bool, len(model))
+	for variable, value := range model {
+		s.phases[variable] = value
+	}
+}
+
+// value returns 1 if the literal is true, -1 if it is false and 0 if it is unassigned
+func (s *Solver) value(literal int) int8 {
+	if literal < 0 {
+		return -s.values[-literal]
+	}
+	return s.values[literal]
+}
+
+// enqueue makes the literal true at the current decision level, implied by the clause with the reason id
+func (s *Solver) enqueue(literal, reason int) {
+	if literal > 0 {
+		s.values[literal] = 1
+	} else {
+		s.values[-literal] = -1
+	}
+	s.level[abs(literal)] = len(s.trailLim)
+	s.reason[abs(literal)] = reason
+	s.trail = append(s.trail, literal)
+}
+
+// undo unassigns every literal on the trail from index start onwards
+func (s *Solver) undo(start int) {
+	for _, literal := range s.trail[start:] {
+		s.values[abs(literal)] = 0
+	}
+	s.trail = s.trail[:start]
+}
+
+// propagate assigns the literal of every unit clause until none is left and reports false on a conflict
+func (s *Solver) propagate() bool {
+	for changed := true; changed; {
+		changed = false
+		for i := 0; i < len(s.clauses)+len(s.lemmas); i++ {
+			clause := s.clause(i + 1)
+			if clause == nil && i >= len(s.clauses) {
+				continue // A deleted lemma
+			}
+			free, unassigned, satisfied := 0, 0, false
+			for _, literal := range clause {
+				if v := s.value(literal); v == 1 {
+					satisfied = true
+					break
+				} else if v == 0 {
+					free++
+					unassigned = literal
+				}
+			}
+			if satisfied {
+				continue
+			}
+			if free == 0 {
+				s.conflict = i + 1
+				return false // Conflict detected
+			}
+			if free == 1 {
+				s.enqueue(unassigned, i+1)
+				s.stats.Propagations++
+				changed = true
+			}
+		}
+	}
+	return true
+}
+
+// clause returns the clause with the id: the clauses added come first, then the learned ones
+func (s *Solver) clause(id int) Clause {
+	if id <= len(s.clauses) {
+		return s.clauses[id-1]
+	}
+	return s.lemmas[id-len(s.clauses)-1]
+}
+
+// deleteLemmas deletes the lemmas at the given indices of s.lemmas, none of which may be the reason
+// of an assignment, and compacts the lemma store once enough have been deleted
+func (s *Solver) deleteLemmas(indices []int) {
+	for _, i := range indices {
+		if s.lemmas[i] != nil {
+			s.lemmas[i] = nil
+			s.deleted++
+			s.stats.Deleted++
+		}
+	}
+	if s.deleted >= s.compactAfter {
+		s.compact()
+	}
+}
+
+// compact rebuilds the lemma store without the deleted lemmas and those satisfied at level 0,
+// and renumbers the lemma ids recorded as reasons on the trail
+func (s *Solver) compact() {
+	locked := s.lockedLemmas()
+	ids := make(map[int]int) // Old lemma id to new
+	kept, activity := s.lemmas[:0], s.activity[:0]
+	for i, lemma := range s.lemmas {
+		id := len(s.clauses) + i + 1
+		if !locked[id] && (lemma == nil || s.satisfiedAtRoot(lemma)) {
+			continue
+		}
+		kept, activity = append(kept, lemma), append(activity, s.activity[i])
+		ids[id] = len(s.clauses) + len(kept)
+	}
+	clear(s.lemmas[len(kept):]) // Let the dropped clauses be collected
+	s.lemmas, s.activity = kept, activity
+	for _, literal := range s.trail {
+		if id := s.reason[abs(literal)]; id > len(s.clauses) {
+			s.reason[abs(literal)] = ids[id]
+		}
+	}
+	s.deleted = 0
+}
+
+// lockedLemmas returns the ids of the lemmas that are the reason of an assignment on the trail
+func (s *Solver) lockedLemmas() map[int]bool {
+	locked := make(map[int]bool)
+	for _, literal := range s.trail {
+		if id := s.reason[abs(literal)]; id > len(s.clauses) {
+			locked[id] = true
+		}
+	}
+	return locked
+}
+
+// satisfiedAtRoot reports whether a literal of the clause is true at level 0
+func (s *Solver) satisfiedAtRoot(clause Clause) bool {
+	for _, literal := range clause {
+		if s.value(literal) == 1 && s.level[abs(literal)] == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// decide returns the literal to try for the first unassigned variable of the first clause
+// not yet satisfied, preferring the variable order given by WithVariableOrder,
+// or returns 0 when every clause is satisfied
+func (s *Solver) decide() int {
+	if s.decider != nil {
+		return s.decideWith(s.decider)
+	}
+	first := 0
+	var active map[int]bool // Unassigned variables of unsatisfied clauses, when an order is set
+	if len(s.order) > 0 {
+		active = make(map[int]bool)
+	}
+	for _, clause := range s.clauses {
+		unassigned := []int{}
+		satisfied := false
+		for _, literal := range clause {
+			if v := s.value(literal); v == 1 {
+				satisfied = true
+				break
+			} else if v == 0 {
+				unassigned = append(unassigned, abs(literal))
+			}
+		}
+		if satisfied || len(unassigned) == 0 {
+			continue
+		}
+		if first == 0 {
+			first = unassigned[0]
+		}
+		if active == nil {
+			break
+		}
+		for _, variable := range unassigned {
+			active[variable] = true
+		}
+	}
+	for _, variable := range s.order {
+		if active[variable] {
+			return s.phase(variable)
+		}
+	}
+	if first == 0 {
+		return 0
+	}
+	return s.phase(first)
+}
+
+// decideWith returns the literal to try for the variable chosen by the decider,
+// or 0 when every clause is satisfied
+func (s *Solver) decideWith(d Decider) int {
+	active := []Clause{}
+	for _, clause := range s.clauses {
+		reduced := Clause{}
+		satisfied := false
+		for _, literal := range clause {
+			if v := s.value(literal); v == 1 {
+				satisfied = true
+				break
+			} else if v == 0 {
+				reduced = append(reduced, literal)
+			}
+		}
+		if !satisfied && len(reduced) > 0 {
+			active = append(active, reduced)
+		}
+	}
+	if len(active) == 0 {
+		return 0
+	}
+	if sc, ok := d.(scorer); ok {
+		return s.phase(bestVariable(sc.scores(active), s.less))
+	}
+	return s.phase(d.Decide(active))
+}
+
+// phase returns the literal of the variable to try first: its saved phase if any,
+// otherwise the choice of the polarity strategy
+func (s *Solver) phase(variable int) int {
+	if value, exists := s.phases[variable]; exists {
+		if value {
+			return variable
+		}
+		return -variable
+	}
+	switch s.polarity {
+	case PolarityFalse:
+		return -variable
+	case PolarityOccurrence:
+		if s.occurrences[-variable] > s.occurrences[variable] {
+			return -variable
+		}
+	}
+	return variable
+}
+
+// backtrack undoes decision levels until one whose decision has not been negated yet, then negates it.
+// It reports false when every decision has been tried both ways.
+func (s *Solver) backtrack() bool {
+	for len(s.trailLim) > 0 {
+		last := len(s.trailLim) - 1
+		start := s.trailLim[last]
+		decision := s.trail[start]
+		s.undo(start)
+		if !s.flipped[last] {
+			s.flipped[last] = true
+			s.enqueue(-decision, s.learned)
+			return true
+		}
+		s.trailLim = s.trailLim[:last]
+		s.flipped = s.flipped[:last]
+	}
+	return false
+}
+
+// analyze learns a clause from the current conflict with the analyzer and jumps back to the
+// level it gives, or reports false when the conflict does not depend on any decision
+func (s *Solver) analyze() bool {
+	if len(s.trailLim) == 0 {
+		return false
+	}
+	bumped := make(map[int]bool) // Lemmas taking part in the conflict
+	bump := func(id int) {
+		if id > len(s.clauses) && !bumped[id] {
+			bumped[id] = true
+			s.bumpLemma(id)
+		}
+	}
+	bump(s.conflict)
+	reason := func(literal int) Clause {
+		if id := s.reason[abs(literal)]; id != 0 {
+			bump(id)
+			return s.clause(id)
+		}
+		return nil
+	}
+	level := func(variable int) int {
+		return s.level[variable]
+	}
+	learned, backjump := s.analyzer.Analyze(s.clause(s.conflict), s.trail, reason, level)
+	if len(learned) == 0 {
+		return false
+	}
+	if s.learnedHook != nil {
+		levels := make(map[int]bool)
+		for _, literal := range learned {
+			levels[s.level[abs(literal)]] = true
+		}
+		s.learnedHook(append(Clause{}, learned...), len(levels))
+	}
+	if backjump < 0 {
+		backjump = 0
+	}
+	if s.chronological && backjump < len(s.trailLim)-1 {
+		backjump = len(s.trailLim) - 1
+	}
+	if backjump < len(s.trailLim) {
+		s.undo(s.trailLim[backjump])
+		s.trailLim = s.trailLim[:backjump]
+		s.flipped = s.flipped[:backjump]
+	}
+	if s.maxLearned > 0 {
+		s.makeRoom(s.maxLearned - 1)
+	}
+	s.lemmas = append(s.lemmas, append(Clause{}, learned...)) // The analyzer may reuse its clause
+	s.activity = append(s.activity, 0)
+	s.bumpLemma(len(s.clauses) + len(s.lemmas))
+	s.activityInc /= lemmaActivityDecay
+	if float64(len(s.lemmas)-s.deleted) >= s.maxLemmas {
+		s.reduceLemmas()
+		s.maxLemmas *= lemmaLimitGrowth
+	}
+	return true
+}
+
+// bumpLemma raises the activity of the lemma with the id, rescaling every activity when they
+// grow too large
+func (s *Solver) bumpLemma(id int) {
+	i := id - len(s.clauses) - 1
+	s.activity[i] += s.activityInc
+	if s.activity[i] > 1e20 {
+		for j := range s.activity {
+			s.activity[j] *= 1e-20
+		}
+		s.activityInc *= 1e-20
+	}
+}
+
+// reduceLemmas deletes the less active half of the lemmas that are not the reason of an
+// assignment, sparing the newest one, which is about to propagate
+func (s *Solver) reduceLemmas() {
+	candidates := s.unlockedLemmas(len(s.lemmas) - 1)
+	s.stats.Reductions++
+	s.deleteLemmas(candidates[:len(candidates)/2])
+}
+
+// makeRoom deletes the least active lemmas until at most limit are kept. When more than limit
+// lemmas are reasons of assignments it first restarts from level 0, where the lemma reasons are
+// dropped.
+func (s *Solver) makeRoom(limit int) {
+	kept := len(s.lemmas) - s.deleted
+	if kept <= limit {
+		return
+	}
+	if len(s.lockedLemmas()) > limit {
+		if len(s.trailLim) > 0 {
+			s.undo(s.trailLim[0])
+		}
+		s.trailLim, s.flipped = nil, nil
+		for _, literal := range s.trail {
+			if s.reason[abs(literal)] > len(s.clauses) {
+				s.reason[abs(literal)] = 0
+			}
+		}
+	}
+	s.stats.Reductions++
+	s.deleteLemmas(s.unlockedLemmas(len(s.lemmas))[:kept-limit])
+}
+
+// unlockedLemmas returns the indices of the lemmas among the first n that are neither deleted nor
+// the reason of an assignment, least active first
+func (s *Solver) unlockedLemmas(n int) []int {
+	locked := s.lockedLemmas()
+	candidates := []int{}
+	for i, lemma := range s.lemmas[:n] {
+		if lemma != nil && !locked[len(s.clauses)+i+1] {
+			candidates = append(candidates, i)
+		}
+	}
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return s.activity[candidates[a]] < s.activity[candidates[b]]
+	})
+	return candidates
+}
+
+// LearnedClause is a clause kept by the solver's conflict analysis with its activity, which grows
+// each time the clause takes part in a conflict and decays as later conflicts are analyzed
+type LearnedClause struct {
+	Clause   Clause
+	Activity float64
+}
+
+// LearnedClauses returns the clauses learned with WithConflictAnalyzer that are still kept, oldest
+// first, for tuning the deletion policy
+func (s *Solver) LearnedClauses() []LearnedClause {
+	learned := []LearnedClause{}
+	for i, lemma := range s.lemmas {
+		if lemma != nil {
+			learned = append(learned, LearnedClause{Clause: append(Clause{}, lemma...), Activity: s.activity[i]})
+		}
+	}
+	return learned
+}
+
+// learn derives the lemma for the current conflict, the negation of the decisions not flipped yet,
+// which is the empty clause once every decision has been flipped. It passes the lemma to the hook
+// and writes it to the proof.
+func (s *Solver) learn() error {
+	lemma := Clause{}
+	for level, start := range s.trailLim {
+		if !s.flipped[level] {
+			lemma = append(lemma, -s.trail[start])
+		}
+	}
+	if s.learnedHook != nil {
+		s.learnedHook(append(Clause{}, lemma...), len(lemma)) // Every literal has its own level
+	}
+	if s.proof == nil {
+		return nil
+	}
+	line := []string{strconv.Itoa(s.nextID)}
+	for _, literal := range lemma {
+		line = append(line, strconv.Itoa(literal))
+	}
+	line = append(line, "0")
+	for _, literal := range s.trail {
+		if id := s.reason[abs(literal)]; id != 0 {
+			line = append(line, strconv.Itoa(id))
+		}
+	}
+	line = append(line, strconv.Itoa(s.conflict), "0")
+	if _, err := fmt.Fprintln(s.proof, strings.Join(line, " ")); err != nil {
+		return err
+	}
+	s.learned = s.nextID
+	s.nextID++
+	return nil
+}
+
+// Solve searches for a satisfying assignment of the clauses added so far
+func (s *Solver) Solve() bool {
+	sat, _ := s.SolveContext(context.Background())
+	return sat
+}
+
+// SolveUnder is Solve with the assumptions, literals taken as true for this call only.
+// When it returns false, FailedAssumptions tells which of them are responsible.
+func (s *Solver) SolveUnder(assumptions []int) bool {
+	s.assumptions = append([]int{}, assumptions...)
+	s.paused = false
+	sat, _ := s.SolveContext(context.Background())
+	s.failed = nil
+	if !sat {
+		s.failed = s.minimalCore()
+	}
+	s.assumptions = nil
+	return sat
+}
+
+// FailedAssumptions returns the assumptions of the last unsatisfiable SolveUnder that suffice for
+// unsatisfiability. The subset is minimal: dropping any of them makes the formula satisfiable.
+// It is empty when the clauses are unsatisfiable on their own.
+func (s *Solver) FailedAssumptions() []int {
+	return s.failed
+}
+
+// minimalCore shrinks the current assumptions to a minimal subset that is still unsatisfiable
+// with the clauses, trying to drop each in turn on a scratch solver
+func (s *Solver) minimalCore() []int {
+	core := append([]int{}, s.assumptions...)
+	for i := 0; i < len(core); {
+		scratch := NewSolver()
+		for _, clause := range s.clauses {
+			scratch.AddClause(clause)
+		}
+		scratch.assumptions = append(append([]int{}, core[:i]...), core[i+1:]...)
+		if sat, _ := scratch.SolveContext(context.Background()); sat {
+			i++ // core[i] is needed
+		} else {
+			core = scratch.assumptions
+		}
+	}
+	return core
+}
+
+// SolveContext is Solve that stops with the context's error once ctx is cancelled.
+// The search state is kept, so the next call (possibly after SaveState and LoadState) resumes it.
+func (s *Solver) SolveContext(ctx context.Context) (bool, error) {
+	if !s.paused {
+		s.undo(0)
+		s.trailLim, s.flipped, s.lemmas, s.deleted = nil, nil, nil, 0
+		s.activity, s.activityInc = nil, 1
+		s.maxLemmas = max(float64(len(s.clauses))/3, minLemmaLimit)
+		s.stats = Stats{}
+		s.nextID = len(s.clauses) + 1
+		for _, literal := range s.assumptions { // Assumptions hold at level 0 and are never flipped
+			s.grow(abs(literal))
+			switch s.value(literal) {
+			case -1:
+				s.stats.ProofReason = ProofPropagation
+				return false, nil
+			case 0:
+				s.enqueue(literal, 0)
+			}
+		}
+		for i, clause := range s.clauses { // Input units are forced at level 0 before any decision
+			if len(clause) == 1 && s.value(clause[0]) == 0 {
+				s.enqueue(clause[0], i+1)
+			}
+		}
+		// A unit contradicting an earlier one is left to propagation, which reports the conflict
+		// and, with WithLRATProof, derives the empty clause from the two units
+	}
+	s.paused = false
+	s.lastProgress = time.Now()
+	start := time.Now()
+	defer func() {
+		s.stats.SolveTime += time.Since(start)
+	}()
+	if s.polarity == PolarityOccurrence {
+		s.occurrences = OccurrenceCounts(s.clauses)
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			s.paused = true
+			return false, err
+		}
+		if s.progress != nil && time.Since(s.lastProgress) >= s.progressInterval {
+			s.progress(s.stats)
+			s.lastProgress = time.Now()
+		}
+		mark := time.Now()
+		ok := s.propagate()
+		s.stats.PropagationTime += time.Since(mark)
+		if !ok {
+			mark = time.Now()
+			s.stats.Conflicts++
+			if s.analyzer != nil {
+				ok = s.analyze()
+			} else {
+				if s.proof != nil || s.learnedHook != nil {
+					if err := s.learn(); err != nil {
+						return false, err
+					}
+				}
+				ok = s.backtrack()
+			}
+			s.stats.ConflictTime += time.Since(mark)
+			if !ok {
+				s.stats.ProofReason = ProofSearch
+				if s.stats.Decisions == 0 {
+					s.stats.ProofReason = ProofPropagation
+				}
+				for _, clause := range s.clauses {
+					if len(clause) == 0 {
+						s.stats.ProofReason = ProofEmptyClause
+					}
+				}
+				return false, nil
+			}
+			continue
+		}
+		mark = time.Now()
+		literal := s.decide()
+		s.stats.DecisionTime += time.Since(mark)
+		if literal == 0 {
+			return true, nil
+		}
+		s.stats.Decisions++
+		s.trailLim = append(s.trailLim, len(s.trail))
+		s.flipped = append(s.flipped, false)
+		s.enqueue(literal, 0)
+	}
+}
+
+// Model returns the assignment found by the last successful Solve; variables left free are set to true
+func (s *Solver) Model() map[int]bool {
+	model := make(map[int]bool)
+	for variable := 1; variable <= s.numVars; variable++ {
+		model[variable] = s.values[variable] != -1
+	}
+	return model
+}
+
+// Reason returns the clause that forced the literal true by unit propagation in the current or
+// last search, or nil when the literal is not true, is a decision, or is a flipped decision
+func (s *Solver) Reason(literal int) *Clause {
+	if abs(literal) > s.numVars || s.value(literal) != 1 {
+		return nil
+	}
+	id := s.reason[abs(literal)]
+	switch {
+	case id == 0 || id > len(s.clauses)+len(s.lemmas): // Decisions and lemma ids from WithLRATProof
+		return nil
+	case id > len(s.clauses):
+		return &s.lemmas[id-len(s.clauses)-1]
+	}
+	return &s.clauses[id-1]
+}
+
+// DecisionLevels returns the decision level at which each assigned variable got its value in the
+// last solve. Level 0 holds values forced before any decision.
+func (s *Solver) DecisionLevels() map[int]int {
+	levels := make(map[int]int)
+	for _, literal := range s.trail {
+		levels[abs(literal)] = s.level[abs(literal)]
+	}
+	return levels
+}
+
+// solverStateVersion is the version of the format written by SaveState
+const solverStateVersion = 1
+
+// solverState is the serialized form of a Solver
+type solverState struct {
+	Version       int
+	Clauses       []Clause
+	Lemmas        []Clause
+	Activity      []float64
+	ActivityInc   float64
+	MaxLemmas     float64
+	MaxLearned    int
+	Deleted       int
+	CompactAfter  int
+	NumVars       int
+	Trail         []int
+	TrailLim      []int
+	Flipped       []bool
+	Reasons       []int // Per variable, as in Solver.reason
+	Stats         Stats
+	Paused        bool
+	Polarity      Polarity
+	Learning      bool
+	Chronological bool
+	Order         []int
+	Phases        map[int]bool
+	ClauseCheck   ClauseCheck
+	Failed        []int
+	NextID        int
+	Learned       int
+}
+
+// SaveState writes the clauses and learned lemmas, the search trail with the reason of every
+// assignment, the failed assumptions, the proof ids, the statistics and the heuristic settings in
+// gob format so that an interrupted solve can be resumed by LoadState, possibly elsewhere.
+// Callbacks such as WithProgress and writers are not saved.
+func (s *Solver) SaveState(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(solverState{
+		Version:       solverStateVersion,
+		Clauses:       s.clauses,
+		Lemmas:        s.lemmas,
+		Activity:      s.activity,
+		ActivityInc:   s.activityInc,
+		MaxLemmas:     s.maxLemmas,
+		MaxLearned:    s.maxLearned,
+		Deleted:       s.deleted,
+		CompactAfter:  s.compactAfter,
+		NumVars:       s.numVars,
+		Trail:         s.trail,
+		TrailLim:      s.trailLim,
+		Flipped:       s.flipped,
+		Reasons:       s.reason,
+		Stats:         s.stats,
+		Paused:        s.paused,
+		Polarity:      s.polarity,
+		Learning:      s.analyzer != nil,
+		Chronological: s.chronological,
+		Order:         s.order,
+		Phases:        s.phases,
+		ClauseCheck:   s.clauseCheck,
+		Failed:        s.failed,
+		NextID:        s.nextID,
+		Learned:       s.learned,
+	})
+}
+
+// LoadState restores a solver written by SaveState; opts supply the callbacks that are not saved.
+// A solver that learned clauses resumes with the analyzer given in opts, or FirstUIP without one.
+// The clauses are restored as saved, without going through the clause check again.
+func LoadState(r io.Reader, opts ...Option) (*Solver, error) {
+	var state solverState
+	if err := gob.NewDecoder(r).Decode(&state); err != nil {
+		return nil, err
+	}
+	if state.Version != solverStateVersion {
+		return nil, fmt.Errorf("unsupported solver state version %d", state.Version)
+	}
+	if len(state.Reasons) != state.NumVars+1 {
+		return nil, fmt.Errorf("solver state has %d reasons for %d variables", len(state.Reasons), state.NumVars)
+	}
+	for _, literal := range state.Trail {
+		if literal == 0 || abs(literal) > state.NumVars {
+			return nil, fmt.Errorf("solver state has the literal %d on the trail of %d variables", literal, state.NumVars)
+		}
+	}
+	for i, start := range state.TrailLim {
+		if start < 0 || start >= len(state.Trail) || i > 0 && start <= state.TrailLim[i-1] {
+			return nil, fmt.Errorf("solver state has the level %d start at %d, out of order or past the end of the trail", i+1, start)
+		}
+	}
+	if len(state.Activity) != len(state.Lemmas) {
+		return nil, fmt.Errorf("solver state has %d activities for %d lemmas", len(state.Activity), len(state.Lemmas))
+	}
+	s := NewSolver(opts...)
+	s.clauses = state.Clauses
+	s.lemmas = state.Lemmas
+	for i, lemma := range s.lemmas { // Lemmas are never empty, so an empty one was deleted
+		if len(lemma) == 0 {
+			s.lemmas[i] = nil
+		}
+	}
+	s.activity = state.Activity
+	s.activityInc = state.ActivityInc
+	s.maxLemmas = state.MaxLemmas
+	s.maxLearned = state.MaxLearned
+	s.deleted = state.Deleted
+	s.compactAfter = state.CompactAfter
+	s.values = make([]int8, state.NumVars+1)
+	s.level = make([]int, state.NumVars+1)
+	s.reason = make([]int, state.NumVars+1)
+	s.numVars = state.NumVars
+	level := 0 // Replay the trail so every literal gets its decision level back
+	for i, literal := range state.Trail {
+		for level < len(state.TrailLim) && state.TrailLim[level] == i {
+			level++
+		}
+		s.trailLim = state.TrailLim[:level]
+		s.enqueue(literal, state.Reasons[abs(literal)])
+	}
+	s.trailLim = state.TrailLim
+	s.flipped = state.Flipped
+	s.stats = state.Stats
+	s.paused = state.Paused
+	s.polarity = state.Polarity
+	if state.Learning && s.analyzer == nil {
+		s.analyzer = FirstUIP{}
+	}
+	s.chronological = state.Chronological
+	s.order = state.Order
+	s.phases = state.Phases
+	s.clauseCheck = state.ClauseCheck
+	s.failed = state.Failed
+	s.nextID = state.NextID
+	s.learned = state.Learned
+	return s, nil
+}
@@ -0,0 +1,1118 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSolverProofReason(t *testing.T) {
+	tests := []struct {
+		cnf  CNF
+		sat  bool
+		want ProofReason
+	}{
+		{CNF{{1, 2}}, true, ProofNone},
+		{CNF{{1, 2}, {}}, false, ProofEmptyClause},
+		{CNF{{1}, {-1, 2}, {-2, 3}, {-3, -1}}, false, ProofPropagation},
+		{CNF{{1, 2}, {1, -2}, {-1, 2}, {-1, -2}}, false, ProofSearch},
+	}
+	for _, test := range tests {
+		s := NewSolver()
+		for _, clause := range test.cnf {
+			s.AddClause(clause)
+		}
+		if sat := s.Solve(); sat != test.sat || s.Stats().ProofReason != test.want {
+			t.Errorf("%v: got %v, %v, want %v, %v", test.cnf, sat, s.Stats().ProofReason, test.sat, test.want)
+		}
+	}
+}
+
+func TestSolverLRATProof(t *testing.T) {
+	formulas := []CNF{
+		{{1, 2}, {1, -2}, {-1, 2}, {-1, -2}},
+		pigeonhole(3, 0),
+	}
+	for seed := int64(0); len(formulas) < 12; seed++ {
+		if cnf := random3SAT(20, 120, seed); !DPLL(cnf, map[int]bool{}) {
+			formulas = append(formulas, cnf)
+		}
+	}
+	for _, cnf := range formulas {
+		var proof bytes.Buffer
+		s := NewSolver(WithLRATProof(&proof))
+		for _, clause := range cnf {
+			s.AddClause(clause)
+		}
+		if s.Solve() {
+			t.Fatalf("%v: unsatisfiable formula reported satisfiable", cnf)
+		}
+		if err := checkLRAT(cnf, proof.String()); err != nil {
+			t.Fatalf("%v: proof %q: %v", cnf, proof.String(), err)
+		}
+	}
+
+	// The checker must reject a proof whose hints were tampered with
+	cnf := CNF{{1, 2}, {1, -2}, {-1, 2}, {-1, -2}}
+	var proof bytes.Buffer
+	s := NewSolver(WithLRATProof(&proof))
+	for _, clause := range cnf {
+		s.AddClause(clause)
+	}
+	s.Solve()
+	lines := strings.Split(strings.TrimSpace(proof.String()), "\n")
+	fields := strings.Fields(lines[len(lines)-1])
+	fields = append(fields[:len(fields)-2], fields[len(fields)-1]) // Drop the last hint
+	lines[len(lines)-1] = strings.Join(fields, " ")
+	if err := checkLRAT(cnf, strings.Join(lines, "\n")); err == nil {
+		t.Fatalf("a proof missing a hint was accepted: %q", strings.Join(lines, "\n"))
+	}
+}
+
+// checkLRAT verifies an LRAT proof of the CNF: every lemma must follow by unit propagation over
+// its hints, and the proof must derive the empty clause
+func checkLRAT(cnf CNF, proof string) error {
+	clauses := make(map[int]Clause)
+	for i, clause := range cnf {
+		clauses[i+1] = clause
+	}
+	for _, line := range strings.Split(strings.TrimSpace(proof), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			return fmt.Errorf("short line %q", line)
+		}
+		numbers := make([]int, len(fields))
+		for i, field := range fields {
+			n, err := strconv.Atoi(field)
+			if err != nil {
+				return fmt.Errorf("bad number in %q", line)
+			}
+			numbers[i] = n
+		}
+		i := 1
+		lemma := Clause{}
+		for ; i < len(numbers) && numbers[i] != 0; i++ {
+			lemma = append(lemma, numbers[i])
+		}
+		assignment := make(map[int]bool)
+		for _, literal := range lemma {
+			assignment[abs(literal)] = literal < 0
+		}
+		conflict := false
+		for i++; i < len(numbers) && numbers[i] != 0 && !conflict; i++ {
+			hint, exists := clauses[numbers[i]]
+			if !exists {
+				return fmt.Errorf("unknown hint %d in %q", numbers[i], line)
+			}
+			free := Clause{}
+			for _, literal := range hint {
+				if value, assigned := assignment[abs(literal)]; !assigned {
+					free = append(free, literal)
+				} else if value == (literal > 0) {
+					return fmt.Errorf("hint %d is satisfied in %q", numbers[i], line)
+				}
+			}
+			switch len(free) {
+			case 0:
+				conflict = true
+			case 1:
+				assignment[abs(free[0])] = free[0] > 0
+			default:
+				return fmt.Errorf("hint %d is not unit in %q", numbers[i], line)
+			}
+		}
+		if !conflict {
+			return fmt.Errorf("hints do not refute %q", line)
+		}
+		if len(lemma) == 0 {
+			return nil
+		}
+		clauses[numbers[0]] = lemma
+	}
+	return fmt.Errorf("no empty clause derived")
+}
+
+func TestSolverContradictoryInputUnits(t *testing.T) {
+	cnf := CNF{{1}, {2, 3}, {-1}}
+	var proof bytes.Buffer
+	hooked := 0
+	s := NewSolver(WithLRATProof(&proof), WithLearnedClauseHook(func(Clause, int) { hooked++ }))
+	for _, clause := range cnf {
+		s.AddClause(clause)
+	}
+	if s.Solve() {
+		t.Fatal("contradictory units reported satisfiable")
+	}
+	stats := s.Stats()
+	if stats.Decisions != 0 || stats.Conflicts != 1 || stats.ProofReason != ProofPropagation || stats.SolveTime == 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if hooked != 1 {
+		t.Errorf("learned clause hook ran %d times, want 1", hooked)
+	}
+	if err := checkLRAT(cnf, proof.String()); err != nil {
+		t.Errorf("proof %q: %v", proof.String(), err)
+	}
+}
+
+func TestProofReasonString(t *testing.T) {
+	tests := map[ProofReason]string{
+		ProofNone:       "none",
+		ProofSearch:     "search",
+		ProofReason(7):  "ProofReason(7)",
+		ProofReason(-1): "ProofReason(-1)",
+	}
+	for reason, want := range tests {
+		if got := reason.String(); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+}
+
+func TestSolverAddClauses(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	cnf := CNF{}
+	for i := 0; i < 10000; i++ {
+		a, b := rng.Intn(3000)+1, rng.Intn(3000)+1
+		cnf = append(cnf, Clause{a, -b, a + 1})
+	}
+	stream := func(cnf CNF) <-chan Clause {
+		clauses := make(chan Clause)
+		go func() {
+			for _, clause := range cnf {
+				clauses <- clause
+			}
+			close(clauses)
+		}()
+		return clauses
+	}
+	streamed, batch := NewSolver(), NewSolver()
+	if err := streamed.AddClauses(stream(cnf)); err != nil {
+		t.Fatal(err)
+	}
+	for _, clause := range cnf {
+		batch.AddClause(clause)
+	}
+	if streamed.Solve() != batch.Solve() || len(UnsatisfiedClauses(cnf, streamed.Model())) != 0 {
+		t.Fatal("the streamed formula solved differently from the batch one")
+	}
+
+	rejecting := NewSolver(WithClauseCheck(ClauseReject))
+	err := rejecting.AddClauses(stream(CNF{{1, 2}, {3, -3}, {-1}, {2, 2}}))
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("got %v, want the first ValidationError", err)
+	}
+	if !rejecting.Solve() || !rejecting.Model()[2] {
+		t.Fatal("the valid clauses should still be added")
+	}
+}
+
+func TestSolverSaveLoadState(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		cnf := append(CNF{{1, -1}}, random3SAT(40, 170, seed)...) // The tautology must survive the reload
+		order := rand.New(rand.NewSource(seed)).Perm(40)
+		for i := range order {
+			order[i]++
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+		s := NewSolver(WithClauseLearning(), WithVariableOrder(order), WithProgress(0, func(Stats) {
+			if calls++; calls == 20 {
+				cancel()
+			}
+		}))
+		for _, clause := range cnf {
+			s.AddClause(clause)
+		}
+		if _, err := s.SolveContext(ctx); err == nil {
+			continue // Solved before the interruption
+		}
+		var buf bytes.Buffer
+		if err := s.SaveState(&buf); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := LoadState(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(loaded.clauses) != len(s.clauses) || len(loaded.lemmas) != len(s.lemmas) || loaded.nextID != s.nextID {
+			t.Fatalf("seed %d: clauses, lemmas or proof ids were lost", seed)
+		}
+		for _, literal := range s.trail {
+			if want, got := s.reason[abs(literal)], loaded.reason[abs(literal)]; got != want {
+				t.Fatalf("seed %d: reason of %d changed from %d to %d", seed, literal, want, got)
+			}
+		}
+		if want, got := fmt.Sprint(s.DecisionLevels()), fmt.Sprint(loaded.DecisionLevels()); got != want {
+			t.Fatalf("seed %d: decision levels changed from %s to %s", seed, want, got)
+		}
+
+		// Resuming the loaded solver must follow the same search as resuming the original
+		s.progress = nil
+		want := s.Solve()
+		got := loaded.Solve()
+		if got != want || loaded.Stats().Decisions != s.Stats().Decisions || loaded.Stats().Conflicts != s.Stats().Conflicts {
+			t.Fatalf("seed %d: resumed to %v after %+v, want %v after %+v", seed, got, loaded.Stats(), want, s.Stats())
+		}
+		if got {
+			model := loaded.Model()
+			for _, clause := range cnf {
+				if !clauseSatisfied(clause, model) {
+					t.Fatalf("seed %d: the model falsifies %v", seed, clause)
+				}
+			}
+		}
+	}
+}
+
+func TestLoadStateMalformed(t *testing.T) {
+	s := NewSolver(WithClauseLearning())
+	for _, clause := range random3SAT(20, 85, 3) {
+		s.AddClause(clause)
+	}
+	s.Solve()
+	var buf bytes.Buffer
+	if err := s.SaveState(&buf); err != nil {
+		t.Fatal(err)
+	}
+	saved := buf.Bytes()
+	for name, corrupt := range map[string]func(state *solverState){
+		"unknown variable":    func(state *solverState) { state.Trail = append(state.Trail, state.NumVars+1) },
+		"zero literal":        func(state *solverState) { state.Trail = append(state.Trail, 0) },
+		"levels out of order": func(state *solverState) { state.TrailLim = []int{1, 1} },
+		"level past the end":  func(state *solverState) { state.TrailLim = []int{len(state.Trail)} },
+		"extra activity":      func(state *solverState) { state.Activity = append(state.Activity, 1) },
+	} {
+		var state solverState
+		if err := gob.NewDecoder(bytes.NewReader(saved)).Decode(&state); err != nil {
+			t.Fatal(err)
+		}
+		corrupt(&state)
+		var corrupted bytes.Buffer
+		if err := gob.NewEncoder(&corrupted).Encode(state); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadState(&corrupted); err == nil {
+			t.Errorf("%s: the state was loaded", name)
+		}
+	}
+	if _, err := LoadState(bytes.NewReader(saved)); err != nil {
+		t.Fatalf("the saved state itself: %v", err)
+	}
+}
+
+func TestSolverAddClauseWhilePaused(t *testing.T) {
+	paused := 0
+	for seed := int64(0); seed < 20; seed++ {
+		cnf := random3SAT(40, 170, seed)
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+		s := NewSolver(WithClauseLearning(), WithProgress(0, func(Stats) {
+			if calls++; calls == 20 {
+				cancel()
+			}
+		}))
+		for _, clause := range cnf {
+			s.AddClause(clause)
+		}
+		if _, err := s.SolveContext(ctx); err == nil || len(s.lemmas) == 0 {
+			continue // Nothing learned before the interruption
+		}
+		paused++
+		extra := Clause{-s.trail[0], 2} // Added between the input clauses and the lemma ids
+		s.AddClause(extra)
+		cnf = append(cnf, extra)
+		s.progress = nil
+		got, want := s.Solve(), DPLL(cnf, map[int]bool{})
+		if got != want {
+			t.Fatalf("seed %d: got %v, want %v", seed, got, want)
+		}
+		if got && len(UnsatisfiedClauses(cnf, s.Model())) != 0 {
+			t.Fatalf("seed %d: the model falsifies a clause", seed)
+		}
+	}
+	if paused == 0 {
+		t.Fatal("no solve was interrupted after learning a clause")
+	}
+}
+
+func TestSolverClauseLearning(t *testing.T) {
+	for seed := int64(0); seed < 30; seed++ {
+		cnf := random3SAT(30, 128, seed)
+		s := NewSolver(WithClauseLearning())
+		for _, clause := range cnf {
+			s.AddClause(clause)
+		}
+		got := s.Solve()
+		if want := DPLL(cnf, map[int]bool{}); got != want {
+			t.Fatalf("seed %d: got %v, want %v", seed, got, want)
+		}
+		if got && len(UnsatisfiedClauses(cnf, s.Model())) != 0 {
+			t.Fatalf("seed %d: the model falsifies a clause", seed)
+		}
+		for _, lemma := range s.lemmas { // Every learned clause must follow from the formula
+			refutation := append(CNF{}, cnf...)
+			for _, literal := range lemma {
+				refutation = append(refutation, Clause{-literal})
+			}
+			if DPLL(refutation, map[int]bool{}) {
+				t.Fatalf("seed %d: the learned clause %v is not implied", seed, lemma)
+			}
+		}
+	}
+
+	var decisions [2]int
+	for i, opts := range [][]Option{nil, {WithClauseLearning()}} {
+		s := NewSolver(opts...)
+		for _, clause := range pigeonhole(5, 0) {
+			s.AddClause(clause)
+		}
+		if s.Solve() {
+			t.Fatal("the pigeonhole formula was reported satisfiable")
+		}
+		decisions[i] = s.Stats().Decisions
+	}
+	if decisions[1] >= decisions[0] {
+		t.Fatalf("learning took %d decisions, plain backtracking %d", decisions[1], decisions[0])
+	}
+}
+
+func TestSolverChronologicalBacktracking(t *testing.T) {
+	differs := false
+	for seed := int64(0); seed < 30; seed++ {
+		cnf := random3SAT(30, 128, seed)
+		want := DPLL(cnf, map[int]bool{})
+		var decisions [2]int
+		for i, chronological := range []bool{false, true} {
+			s := NewSolver(WithClauseLearning(), WithChronologicalBacktracking(chronological))
+			for _, clause := range cnf {
+				s.AddClause(clause)
+			}
+			got := s.Solve()
+			if got != want {
+				t.Fatalf("seed %d, chronological %v: got %v, want %v", seed, chronological, got, want)
+			}
+			if got && len(UnsatisfiedClauses(cnf, s.Model())) != 0 {
+				t.Fatalf("seed %d, chronological %v: the model falsifies a clause", seed, chronological)
+			}
+			decisions[i] = s.Stats().Decisions
+		}
+		differs = differs || decisions[0] != decisions[1]
+	}
+	if !differs {
+		t.Fatal("the option never changed the search")
+	}
+}
+
+func TestSolverCompact(t *testing.T) {
+	compacted := 0
+	for seed := int64(0); seed < 20; seed++ {
+		cnf := random3SAT(40, 170, seed)
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+		s := NewSolver(WithClauseLearning(), WithCompactionThreshold(5), WithProgress(0, func(Stats) {
+			if calls++; calls == 30 {
+				cancel()
+			}
+		}))
+		for _, clause := range cnf {
+			s.AddClause(clause)
+		}
+		if _, err := s.SolveContext(ctx); err == nil {
+			continue
+		}
+
+		// Delete every lemma that is not the reason of an assignment
+		locked := make(map[int]bool)
+		for _, literal := range s.trail {
+			locked[s.reason[abs(literal)]] = true
+		}
+		unlocked := []int{}
+		for i := range s.lemmas {
+			if !locked[len(s.clauses)+i+1] {
+				unlocked = append(unlocked, i)
+			}
+		}
+		reasons := make(map[int]string)
+		for _, literal := range s.trail {
+			if id := s.reason[abs(literal)]; id != 0 {
+				reasons[literal] = fmt.Sprint(s.clause(id))
+			}
+		}
+		before := len(s.lemmas)
+		s.deleteLemmas(unlocked)
+		if len(unlocked) >= 5 {
+			compacted++
+			if len(s.lemmas) > before-len(unlocked) || s.deleted != 0 {
+				t.Fatalf("seed %d: the compaction kept deleted lemmas", seed)
+			}
+			for _, lemma := range s.lemmas {
+				if lemma == nil {
+					t.Fatalf("seed %d: a deleted lemma survived the compaction", seed)
+				}
+			}
+		}
+		for _, literal := range s.trail { // Reasons must still point at the same clauses
+			reason := ""
+			if id := s.reason[abs(literal)]; id != 0 {
+				reason = fmt.Sprint(s.clause(id))
+			}
+			if reason != reasons[literal] {
+				t.Fatalf("seed %d: the reason of %d changed from %s to %s", seed, literal, reasons[literal], reason)
+			}
+		}
+
+		s.progress = nil
+		got := s.Solve()
+		if want := DPLL(cnf, map[int]bool{}); got != want {
+			t.Fatalf("seed %d: got %v, want %v", seed, got, want)
+		}
+		if got && len(UnsatisfiedClauses(cnf, s.Model())) != 0 {
+			t.Fatalf("seed %d: the model falsifies a clause", seed)
+		}
+	}
+	if compacted == 0 {
+		t.Fatal("no instance had enough lemmas to compact")
+	}
+}
+
+func TestSolverLemmaActivity(t *testing.T) {
+	cnf := random3SAT(40, 170, 3)
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	s := NewSolver(WithClauseLearning(), WithCompactionThreshold(1000), WithProgress(0, func(Stats) {
+		if calls++; calls == 40 {
+			cancel()
+		}
+	}))
+	for _, clause := range cnf {
+		s.AddClause(clause)
+	}
+	if _, err := s.SolveContext(ctx); err == nil {
+		t.Fatal("the solve should have been interrupted")
+	}
+	locked := s.lockedLemmas()
+	candidates := []int{}
+	for i := range s.lemmas[:len(s.lemmas)-1] {
+		if !locked[len(s.clauses)+i+1] {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) < 4 {
+		t.Fatalf("only %d lemmas can be deleted", len(candidates))
+	}
+	bumped := candidates[:len(candidates)-len(candidates)/2]
+	for _, i := range bumped {
+		for k := 0; k < 10; k++ {
+			s.bumpLemma(len(s.clauses) + i + 1)
+		}
+	}
+	s.reduceLemmas()
+	for _, i := range candidates {
+		if kept := s.lemmas[i] != nil; kept != (i <= bumped[len(bumped)-1]) {
+			t.Fatalf("lemma %d: kept %v, bumped lemmas %v", i, kept, bumped)
+		}
+	}
+	if s.Stats().Reductions != 1 || s.Stats().Deleted != len(candidates)/2 {
+		t.Fatalf("got %+v", s.Stats())
+	}
+	if len(s.LearnedClauses()) != len(s.lemmas)-len(candidates)/2 {
+		t.Fatal("LearnedClauses returned deleted lemmas")
+	}
+}
+
+func TestSolverLemmaReduction(t *testing.T) {
+	reductions := 0
+	for seed := int64(0); seed < 10; seed++ {
+		cnf := random3SAT(60, 256, seed)
+		s := NewSolver(WithClauseLearning())
+		for _, clause := range cnf {
+			s.AddClause(clause)
+		}
+		got := s.Solve()
+		if want := DPLL(cnf, map[int]bool{}); got != want {
+			t.Fatalf("seed %d: got %v, want %v", seed, got, want)
+		}
+		if got && len(UnsatisfiedClauses(cnf, s.Model())) != 0 {
+			t.Fatalf("seed %d: the model falsifies a clause", seed)
+		}
+		reductions += s.Stats().Reductions
+	}
+	if reductions == 0 {
+		t.Fatal("no instance learned enough clauses to be reduced")
+	}
+}
+
+func TestSolverProgress(t *testing.T) {
+	var reports []Stats
+	s := NewSolver(WithProgress(time.Millisecond, func(stats Stats) {
+		reports = append(reports, stats)
+	}))
+	for _, clause := range random3SAT(120, 511, 4) {
+		s.AddClause(clause)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	s.SolveContext(ctx)
+	if len(reports) == 0 {
+		t.Fatalf("no progress report in %v", s.Stats().SolveTime)
+	}
+	for i := 1; i < len(reports); i++ {
+		if reports[i].Decisions < reports[i-1].Decisions || reports[i].Propagations < reports[i-1].Propagations {
+			t.Fatalf("the counts went back from %+v to %+v", reports[i-1], reports[i])
+		}
+	}
+}
+
+func TestSolverOccurrencePolarity(t *testing.T) {
+	// Seven literals in ten are negative, so trying true first mostly leads into conflicts
+	rng := rand.New(rand.NewSource(1))
+	var decisions, conflicts [2]int
+	for round := 0; round < 20; round++ {
+		cnf := CNF{}
+		for i := 0; i < 200; i++ {
+			clause := Clause{}
+			for _, variable := range rng.Perm(40)[:3] {
+				if rng.Intn(10) < 7 {
+					clause = append(clause, -(variable + 1))
+				} else {
+					clause = append(clause, variable+1)
+				}
+			}
+			cnf = append(cnf, clause)
+		}
+		want := DPLL(cnf, map[int]bool{})
+		for i, polarity := range []Polarity{PolarityTrue, PolarityOccurrence} {
+			s := NewSolver(WithDefaultPolarity(polarity))
+			for _, clause := range cnf {
+				s.AddClause(clause)
+			}
+			if s.Solve() != want {
+				t.Fatalf("round %d, polarity %v: wrong answer", round, polarity)
+			}
+			decisions[i] += s.Stats().Decisions
+			conflicts[i] += s.Stats().Conflicts
+		}
+	}
+	if decisions[1] >= decisions[0] || conflicts[1] >= conflicts[0] {
+		t.Fatalf("occurrence polarity: %d decisions and %d conflicts, always true: %d and %d",
+			decisions[1], conflicts[1], decisions[0], conflicts[0])
+	}
+}
+
+func TestSolverDecisionLevels(t *testing.T) {
+	s := NewSolver()
+	for _, clause := range (CNF{{1}, {-1, 2}, {3, 4}, {-3, 5}}) {
+		s.AddClause(clause)
+	}
+	if !s.Solve() {
+		t.Fatal("satisfiable formula")
+	}
+	levels := s.DecisionLevels()
+	if levels[1] != 0 || levels[2] != 0 {
+		t.Fatalf("units and their propagation are at level 0, got %v", levels)
+	}
+	if levels[3] != 1 || levels[5] != 1 {
+		t.Fatalf("the first decision and its propagation are at level 1, got %v", levels)
+	}
+	if _, assigned := levels[4]; assigned {
+		t.Fatalf("4 is never assigned, got %v", levels)
+	}
+}
+
+func TestSolverVariableOrder(t *testing.T) {
+	cnf := CNF{{1, 2}, {3, 4}, {5, 6}, {7, 8}}
+	s := NewSolver(WithVariableOrder([]int{6, 4, 2}))
+	for _, clause := range cnf {
+		s.AddClause(clause)
+	}
+	if !s.Solve() {
+		t.Fatal("satisfiable formula")
+	}
+	levels := s.DecisionLevels()
+	for variable, level := range map[int]int{6: 1, 4: 2, 2: 3, 7: 4} {
+		if levels[variable] != level {
+			t.Fatalf("variable %d should be decided at level %d, got %v", variable, level, levels)
+		}
+	}
+	if stats := s.Stats(); stats.Decisions != 4 {
+		t.Fatalf("got %d decisions, want 4", stats.Decisions)
+	}
+}
+
+func TestSolverStatsTiming(t *testing.T) {
+	s := NewSolver()
+	for _, clause := range pigeonhole(6, 0) {
+		s.AddClause(clause)
+	}
+	if s.Solve() {
+		t.Fatal("7 pigeons do not fit in 6 holes")
+	}
+	stats := s.Stats()
+	if stats.PropagationTime <= 0 || stats.ConflictTime <= 0 || stats.DecisionTime <= 0 {
+		t.Fatalf("durations not populated: %+v", stats)
+	}
+	parts := stats.PropagationTime + stats.ConflictTime + stats.DecisionTime
+	if parts > stats.SolveTime || parts < stats.SolveTime/2 {
+		t.Fatalf("the parts sum to %v, want roughly the solve time %v", parts, stats.SolveTime)
+	}
+}
+
+func TestSolverWarmStart(t *testing.T) {
+	cnf := planted3SAT(60, 250, 21)
+	first := NewSolver()
+	for _, clause := range cnf {
+		first.AddClause(clause)
+	}
+	if !first.Solve() {
+		t.Fatal("planted formula reported unsatisfiable")
+	}
+	model := first.Model()
+
+	// A closely related formula: the same clauses and a few more that the model satisfies
+	related := append(CNF{}, cnf...)
+	for _, clause := range planted3SAT(60, 200, 22) {
+		if len(related) < len(cnf)+10 && len(UnsatisfiedClauses(CNF{clause}, model)) == 0 {
+			related = append(related, clause)
+		}
+	}
+	warm, cold := NewSolver(), NewSolver()
+	for _, clause := range related {
+		warm.AddClause(clause)
+		cold.AddClause(clause)
+	}
+	warm.WarmStart(model)
+	if !warm.Solve() || !cold.Solve() {
+		t.Fatal("the related formula is satisfiable")
+	}
+	if w, c := warm.Stats(), cold.Stats(); w.Conflicts != 0 || w.Decisions >= c.Decisions {
+		t.Fatalf("warm start made %d decisions and %d conflicts, a cold start %d decisions", w.Decisions, w.Conflicts, c.Decisions)
+	}
+}
+
+func TestSolverShortestClauseBranching(t *testing.T) {
+	// Wide clauses over unrelated variables come first, so the default rule decides all of them
+	// before it reaches the binary clauses that make the formula unsatisfiable
+	cnf := CNF{}
+	for i := 0; i < 8; i++ {
+		cnf = append(cnf, Clause{3*i + 1, 3*i + 2, 3*i + 3})
+	}
+	cnf = append(cnf, Clause{100, 101}, Clause{100, -101}, Clause{-100, 101}, Clause{-100, -101})
+	shortest, plain := NewSolver(WithShortestClauseBranching()), NewSolver()
+	for _, clause := range cnf {
+		shortest.AddClause(clause)
+		plain.AddClause(clause)
+	}
+	if shortest.Solve() || plain.Solve() {
+		t.Fatal("unsatisfiable formula reported satisfiable")
+	}
+	if s, p := shortest.Stats().Decisions, plain.Stats().Decisions; s > 2 || s >= p {
+		t.Fatalf("shortest clause branching made %d decisions, the default %d", s, p)
+	}
+
+	rng := rand.New(rand.NewSource(8))
+	for round := 0; round < 200; round++ {
+		n := 3 + rng.Intn(8)
+		cnf := CNF{}
+		for i := 0; i < 4*n; i++ {
+			clause := Clause{}
+			for k := 1 + rng.Intn(4); k > 0; k-- {
+				literal := rng.Intn(n) + 1
+				if rng.Intn(2) == 0 {
+					literal = -literal
+				}
+				clause = append(clause, literal)
+			}
+			cnf = append(cnf, clause)
+		}
+		s := NewSolver(WithShortestClauseBranching())
+		for _, clause := range cnf {
+			s.AddClause(clause)
+		}
+		sat := s.Solve()
+		if sat != DPLL(cnf, map[int]bool{}) || sat && len(UnsatisfiedClauses(cnf, s.Model())) > 0 {
+			t.Fatalf("%v: got %v with model %v", cnf, sat, s.Model())
+		}
+	}
+}
+
+func TestMOMScores(t *testing.T) {
+	// The shortest clauses have two literals: f(1) = 2, f(-1) = 1, f(2) = 1, f(-2) = 1, f(3) = 1
+	clauses := []Clause{{1, 2}, {-1, 3}, {1, -2}, {2, 3, 4}}
+	want := map[int]int{1: 3*4 + 2*1, 2: 2*4 + 1*1, 3: 1*4 + 1*0}
+	if got := momScores(clauses, 2); !reflect.DeepEqual(got, want) {
+		t.Errorf("K = 2: got %v, want %v", got, want)
+	}
+	if got := momScores(clauses, 0); got[1] != 3*16+2 {
+		t.Errorf("K defaults to 4: got %v", got)
+	}
+	if got := (MOMDecider{K: 2}).Decide(clauses); got != 1 {
+		t.Errorf("Decide: got %d, want 1", got)
+	}
+
+	s := NewSolver(WithDecider(MOMDecider{}))
+	for _, clause := range []Clause{{1, 2}, {-1, 2}, {1, -2}, {-1, -2}} {
+		s.AddClause(clause)
+	}
+	if s.Solve() {
+		t.Error("unsatisfiable formula reported satisfiable")
+	}
+}
+
+func TestSolverPropagate(t *testing.T) {
+	s := NewSolver()
+	for _, clause := range []Clause{{-1, 2}, {-2, 3}, {-3, -4}, {4, 5, 6}} {
+		s.AddClause(clause)
+	}
+	assignment := map[int]bool{1: true}
+	if conflict := s.Propagate(assignment); conflict {
+		t.Fatal("deciding 1 causes no conflict")
+	}
+	if want := map[int]bool{1: true, 2: true, 3: true, 4: false}; !reflect.DeepEqual(assignment, want) {
+		t.Fatalf("got %v, want the chain 1 -> 2 -> 3 -> -4", assignment)
+	}
+	if conflict := s.Propagate(map[int]bool{1: true, 4: true}); !conflict {
+		t.Fatal("1 forces -4, so 4 conflicts")
+	}
+	if !s.Solve() {
+		t.Fatal("Propagate must not disturb the search state")
+	}
+}
+
+func TestSolverReason(t *testing.T) {
+	s := NewSolver(WithDefaultPolarity(PolarityTrue))
+	for _, clause := range []Clause{{1, 5}, {-1, 2}, {-2, 3}, {3, 4}} {
+		s.AddClause(clause)
+	}
+	if !s.Solve() {
+		t.Fatal("satisfiable formula")
+	}
+	for literal, want := range map[int]Clause{2: {-1, 2}, 3: {-2, 3}} {
+		if reason := s.Reason(literal); reason == nil || !reflect.DeepEqual(*reason, want) {
+			t.Errorf("Reason(%d): got %v, want %v", literal, reason, want)
+		}
+	}
+	for _, literal := range []int{1, -2, 99} { // A decision, a false literal, an unknown variable
+		if reason := s.Reason(literal); reason != nil {
+			t.Errorf("Reason(%d): got %v, want nil", literal, *reason)
+		}
+	}
+
+	// After backtracking, reasons follow the new trail: 1 is flipped to false, so 5 is forced
+	s = NewSolver(WithDefaultPolarity(PolarityTrue))
+	for _, clause := range []Clause{{1, 5}, {-1, 2}, {-1, -2}} {
+		s.AddClause(clause)
+	}
+	if !s.Solve() || s.Model()[1] {
+		t.Fatal("1 must be false")
+	}
+	if reason := s.Reason(5); reason == nil || !reflect.DeepEqual(*reason, Clause{1, 5}) {
+		t.Errorf("Reason(5) after backtracking: got %v, want (1 OR 5)", reason)
+	}
+	if reason := s.Reason(2); reason != nil {
+		t.Errorf("Reason(2) kept the undone propagation %v", *reason)
+	}
+}
+
+// decisionSequence solves the CNF and returns the decision literals in the order they were made,
+// read off the trail by a progress callback that runs before every propagation
+func decisionSequence(cnf CNF, opts ...Option) []int {
+	decisions := []int{}
+	var s *Solver
+	s = NewSolver(append(opts, WithProgress(0, func(stats Stats) {
+		if stats.Decisions > len(decisions) {
+			decisions = append(decisions, s.trail[s.trailLim[len(s.trailLim)-1]])
+		}
+	}))...)
+	for _, clause := range cnf {
+		s.AddClause(clause)
+	}
+	s.Solve()
+	return decisions
+}
+
+func TestSolverTieBreaking(t *testing.T) {
+	cnf := random3SAT(30, 125, 2)
+	reversed := WithTieBreaking(func(x, y int) bool { return x > y })
+	for _, opts := range [][]Option{
+		{WithShortestClauseBranching()},
+		{WithDecider(MOMDecider{})},
+		{WithShortestClauseBranching(), reversed},
+		{WithDecider(MOMDecider{}), reversed},
+	} {
+		first := decisionSequence(cnf, opts...)
+		if len(first) == 0 {
+			t.Fatal("no decisions were recorded")
+		}
+		for run := 0; run < 5; run++ {
+			if again := decisionSequence(cnf, opts...); !reflect.DeepEqual(again, first) {
+				t.Fatalf("run %d decided %v, the first run %v", run, again, first)
+			}
+		}
+	}
+
+	// Every variable ties at first: the lowest wins by default, the comparator can pick the highest
+	symmetric := CNF{{1, 2}, {3, 4}}
+	if got := decisionSequence(symmetric, WithShortestClauseBranching()); abs(got[0]) != 1 {
+		t.Errorf("default tie-breaking decided %v first, want variable 1", got)
+	}
+	if got := decisionSequence(symmetric, WithShortestClauseBranching(), reversed); abs(got[0]) != 4 {
+		t.Errorf("reversed tie-breaking decided %v first, want variable 4", got)
+	}
+}
+
+func TestSolverFailedAssumptions(t *testing.T) {
+	s := NewSolver()
+	for _, clause := range []Clause{{-1, -2, 5}, {-5, 6}, {-6, -7}, {3, 4}} {
+		s.AddClause(clause)
+	}
+	// 1 and 2 force 5, then 6, which contradicts 7; the other assumptions play no part
+	if s.SolveUnder([]int{3, 1, 4, 2, 8, 7}) {
+		t.Fatal("the assumptions are contradictory")
+	}
+	failed := append([]int{}, s.FailedAssumptions()...)
+	sort.Ints(failed)
+	if want := []int{1, 2, 7}; !reflect.DeepEqual(failed, want) {
+		t.Fatalf("got %v, want %v", failed, want)
+	}
+	if !s.SolveUnder([]int{1, 2}) || len(s.FailedAssumptions()) != 0 {
+		t.Fatalf("1 and 2 alone are consistent, got failed assumptions %v", s.FailedAssumptions())
+	}
+	if s.SolveUnder([]int{9, -9}) || len(s.FailedAssumptions()) != 2 {
+		t.Fatalf("got failed assumptions %v, want 9 and -9", s.FailedAssumptions())
+	}
+	var buf bytes.Buffer
+	if err := s.SaveState(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err := LoadState(&buf); err != nil || !reflect.DeepEqual(loaded.FailedAssumptions(), s.FailedAssumptions()) {
+		t.Fatalf("the failed assumptions were not restored by LoadState (%v)", err)
+	}
+	if !s.Solve() {
+		t.Fatal("the assumptions of earlier calls must not persist")
+	}
+}
+
+func TestStatsJSON(t *testing.T) {
+	stats := Stats{Decisions: 3, Conflicts: 2, Propagations: 9, ProofReason: ProofSearch, Unknown: 1,
+		Reductions: 4, Deleted: 5, PropagationTime: 5 * time.Millisecond, ConflictTime: 7,
+		DecisionTime: time.Second, SolveTime: time.Minute}
+	data, err := json.Marshal(stats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{`"proof_reason":"search"`, `"solve_time_ns":60000000000`} {
+		if !strings.Contains(string(data), field) {
+			t.Errorf("%s lacks %s", data, field)
+		}
+	}
+	var decoded Stats
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded != stats {
+		t.Fatalf("got %+v, %v, want %+v", decoded, err, stats)
+	}
+	if err := json.Unmarshal([]byte(`{"proof_reason":"magic"}`), &decoded); err == nil {
+		t.Fatal("an unknown proof reason must be rejected")
+	}
+}
+
+func TestSolverNewVar(t *testing.T) {
+	s := NewSolver()
+	s.AddClause(Clause{1, 2})
+	s.AddClause(Clause{-1, -2})
+	if !s.Solve() {
+		t.Fatal("got UNSAT before any variable was allocated")
+	}
+	literals := []int{}
+	for i := 0; i < 20; i++ {
+		if variable := s.NewVar(); variable != 3+i {
+			t.Fatalf("got the variable %d, want %d", variable, 3+i)
+		}
+		literals = append(literals, 3+i)
+	}
+	s.AddClause(append(Clause{}, literals...))
+	for _, clause := range AtMostOne(literals, EncodingPairwise, nil) {
+		s.AddClause(clause)
+	}
+	s.AddClause(Clause{-literals[0], 1})
+	s.AddClause(Clause{-1})
+	if !s.Solve() {
+		t.Fatal("got UNSAT with the new variables")
+	}
+	model := s.Model()
+	if len(model) != 22 || len(UnsatisfiedClauses(CNF{{1, 2}, {-1, -2}, {-1}}, model)) != 0 || model[literals[0]] {
+		t.Fatalf("got the model %v", model)
+	}
+	if _, err := DecodeOneHot(model, literals); err != nil {
+		t.Fatal(err)
+	}
+	if variable := s.NewVar(); variable != 23 || !s.Solve() || len(s.Model()) != 23 {
+		t.Fatalf("got the variable %d and a model of %d variables, want 23", variable, len(s.Model()))
+	}
+}
+
+// decisionAnalyzer learns the negation of the decisions on the trail, counting its calls
+type decisionAnalyzer struct {
+	calls *int
+}
+
+func (d decisionAnalyzer) Analyze(conflict Clause, trail []int, reason func(literal int) Clause, level func(variable int) int) (Clause, int) {
+	*d.calls++
+	learned, top := Clause{}, 0
+	for _, literal := range trail {
+		if l := level(abs(literal)); l > 0 && reason(literal) == nil {
+			learned = append(Clause{-literal}, learned...) // Newest decision first
+			if l > top {
+				top = l
+			}
+		}
+	}
+	return learned, top - 1
+}
+
+func TestConflictAnalyzer(t *testing.T) {
+	calls := 0
+	for seed := int64(0); seed < 60; seed++ {
+		cnf := random3SAT(15, 55+int(seed%20), seed)
+		want := DPLL(cnf, map[int]bool{})
+		for _, analyzer := range []ConflictAnalyzer{FirstUIP{}, decisionAnalyzer{&calls}} {
+			s := NewSolver(WithConflictAnalyzer(analyzer))
+			for _, clause := range cnf {
+				s.AddClause(clause)
+			}
+			if got := s.Solve(); got != want {
+				t.Fatalf("seed %d, %T: got %v, want %v", seed, analyzer, got, want)
+			}
+			if want && len(UnsatisfiedClauses(cnf, s.Model())) != 0 {
+				t.Fatalf("seed %d, %T: the model %v falsifies a clause", seed, analyzer, s.Model())
+			}
+			for _, assumptions := range [][]int{{1, -2}, {-1}, {3}} { // Lemmas must not depend on earlier assumptions
+				constrained := append(CNF{}, cnf...)
+				for _, literal := range assumptions {
+					constrained = append(constrained, Clause{literal})
+				}
+				if got := s.SolveUnder(assumptions); got != DPLL(constrained, map[int]bool{}) {
+					t.Fatalf("seed %d, %T: under %v got %v", seed, analyzer, assumptions, got)
+				}
+			}
+		}
+	}
+	if calls == 0 {
+		t.Fatal("the custom analyzer was never called")
+	}
+}
+
+func TestSolverClauseCheck(t *testing.T) {
+	normalizing := NewSolver(WithClauseCheck(ClauseNormalize))
+	if err := normalizing.AddClause(Clause{1, 1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := normalizing.AddClause(Clause{1, -1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []Clause{{1, 2}}; !reflect.DeepEqual(normalizing.clauses, want) {
+		t.Fatalf("normalize: got the clauses %v, want %v", normalizing.clauses, want)
+	}
+
+	rejecting := NewSolver(WithClauseCheck(ClauseReject))
+	rejecting.AddClause(Clause{3})
+	var invalid *ValidationError
+	if err := rejecting.AddClause(Clause{1, 1, 2}); !errors.As(err, &invalid) || invalid.ClauseIndex != 1 {
+		t.Fatalf("reject: got %v, want a *ValidationError for clause 1", err)
+	}
+	if err := rejecting.AddClause(Clause{1, -1}); err == nil {
+		t.Fatal("reject: a tautology was accepted")
+	}
+	if err := rejecting.AddClause(Clause{1, 2}); err != nil || len(rejecting.clauses) != 2 {
+		t.Fatalf("reject: got %v and the clauses %v", err, rejecting.clauses)
+	}
+	var buf bytes.Buffer
+	if err := rejecting.SaveState(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err := LoadState(&buf); err != nil || loaded.AddClause(Clause{2, -2}) == nil {
+		t.Fatalf("reject: the check was not restored by LoadState (%v)", err)
+	}
+
+	keeping := NewSolver()
+	if err := keeping.AddClause(Clause{1, 1, 2}); err != nil || len(keeping.clauses[0]) != 3 {
+		t.Fatalf("by default clauses are kept as given, got %v and %v", err, keeping.clauses)
+	}
+	if !normalizing.Solve() || !rejecting.Solve() || !keeping.Solve() {
+		t.Fatal("got UNSAT for satisfiable clauses")
+	}
+}
+
+func TestSolverMaxLearnedClauses(t *testing.T) {
+	const limit = 8
+	for seed := int64(0); seed < 10; seed++ {
+		cnf := random3SAT(50, 213, seed)
+		var s *Solver
+		largest := 0
+		check := func(Stats) {
+			if kept := len(s.LearnedClauses()); kept > largest {
+				largest = kept
+			}
+		}
+		s = NewSolver(WithClauseLearning(), WithMaxLearnedClauses(limit), WithCompactionThreshold(3), WithProgress(0, check))
+		for _, clause := range cnf {
+			s.AddClause(clause)
+		}
+		got := s.Solve()
+		check(s.Stats())
+		if want := DPLL(cnf, map[int]bool{}); got != want {
+			t.Fatalf("seed %d: got %v, want %v", seed, got, want)
+		}
+		if got && len(UnsatisfiedClauses(cnf, s.Model())) != 0 {
+			t.Fatalf("seed %d: the model falsifies a clause", seed)
+		}
+		if largest > limit {
+			t.Fatalf("seed %d: %d learned clauses were kept, the cap is %d", seed, largest, limit)
+		}
+		if s.Stats().Conflicts > 2*limit && s.Stats().Deleted == 0 {
+			t.Fatalf("seed %d: %d conflicts and nothing deleted", seed, s.Stats().Conflicts)
+		}
+	}
+}
+
+func TestSolverLearnedClauseHook(t *testing.T) {
+	cnf := random3SAT(40, 180, 2)
+	for _, learning := range []bool{false, true} {
+		calls := 0
+		shorter := false
+		opts := []Option{WithLearnedClauseHook(func(clause Clause, lbd int) {
+			calls++
+			shorter = shorter || lbd < len(clause)
+			for i := range clause {
+				clause[i] = 0 // The solver's own copy must not change
+			}
+		})}
+		if learning {
+			opts = append(opts, WithClauseLearning())
+		}
+		s := NewSolver(opts...)
+		for _, clause := range cnf {
+			s.AddClause(clause)
+		}
+		satisfiable := s.Solve()
+		if calls == 0 || calls != s.Stats().Conflicts {
+			t.Fatalf("learning %v: %d hook calls for %d conflicts", learning, calls, s.Stats().Conflicts)
+		}
+		if !learning && shorter {
+			t.Fatal("decision lemmas have one level per literal")
+		}
+		if satisfiable && len(UnsatisfiedClauses(cnf, s.Model())) != 0 {
+			t.Fatalf("learning %v: the model falsifies a clause", learning)
+		}
+	}
+}
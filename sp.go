@@ -0,0 +1,162 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+const (
+	spEpsilon      = 1e-3 // Surveys closer than this are considered converged, or trivial when below it
+	spFraction     = 0.01 // Fraction of the remaining variables fixed in each decimation round
+	spWalkSATFlips = 1000000
+)
+
+// spEdge is the occurrence of a variable at position pos of a clause
+type spEdge struct {
+	clause, pos int
+}
+
+// SurveyPropagation looks for a model of the CNF with survey-inspired decimation: it iterates the
+// survey propagation equations for at most maxIter sweeps, fixes the most biased variables,
+// simplifies by unit propagation and repeats until the surveys become trivial, then solves the
+// residual formula with WalkSAT. It is incomplete: false means no model was found, not that none
+// exists. It suits large random k-SAT near the satisfiability threshold.
+func SurveyPropagation(cnf CNF, numVars int, maxIter int, seed int64) (map[int]bool, bool) {
+	if v := maxVariable(cnf, nil); v > numVars {
+		numVars = v
+	}
+	rng := rand.New(rand.NewSource(seed))
+	assignment := make(map[int]bool)
+	residual, ok := UnitPropagation(cnf, assignment)
+	for ok && len(residual) > 0 {
+		biases := surveys(residual, maxIter, rng)
+		if biases == nil { // Not converged or paramagnetic: leave the rest to local search
+			break
+		}
+		variables := make([]int, 0, len(biases))
+		for variable := range biases {
+			variables = append(variables, variable)
+		}
+		sort.Slice(variables, func(i, j int) bool {
+			bi, bj := math.Abs(biases[variables[i]]), math.Abs(biases[variables[j]])
+			if bi != bj {
+				return bi > bj
+			}
+			return variables[i] < variables[j]
+		})
+		fix := int(spFraction * float64(len(variables)))
+		if fix < 1 {
+			fix = 1
+		}
+		for _, variable := range variables[:fix] {
+			value := biases[variable] > 0
+			assignment[variable] = value
+			residual = assign(residual, variable, value)
+		}
+		residual, ok = UnitPropagation(residual, assignment)
+	}
+	if !ok {
+		return nil, false // Decimation ran into a contradiction
+	}
+
+	model, found := walkSAT(residual, spWalkSATFlips, rng)
+	if !found {
+		return nil, false
+	}
+	for variable, value := range assignment {
+		model[variable] = value
+	}
+	for variable := 1; variable <= numVars; variable++ {
+		if _, exists := model[variable]; !exists {
+			model[variable] = true
+		}
+	}
+	if len(UnsatisfiedClauses(cnf, model)) > 0 {
+		return nil, false
+	}
+	return model, true
+}
+
+// surveys runs survey propagation on the CNF and returns the bias of every variable towards true
+// (positive) or false (negative). It returns nil when the surveys do not converge within maxIter
+// sweeps or converge to the trivial fixed point.
+func surveys(cnf CNF, maxIter int, rng *rand.Rand) map[int]float64 {
+	occurrences := make(map[int][]spEdge)
+	eta := make([][]float64, len(cnf)) // eta[a][k]: probability that clause a warns its k-th variable
+	for a, clause := range cnf {
+		eta[a] = make([]float64, len(clause))
+		for k, literal := range clause {
+			eta[a][k] = rng.Float64()
+			occurrences[abs(literal)] = append(occurrences[abs(literal)], spEdge{a, k})
+		}
+	}
+
+	converged := false
+	for sweep := 0; sweep < maxIter && !converged; sweep++ {
+		converged = true
+		for a, clause := range cnf {
+			for k := range clause {
+				survey := 1.0
+				for m, literal := range clause {
+					if m == k {
+						continue
+					}
+					// Products of 1-eta over the other clauses where the variable has the same or the opposite sign
+					same, opposite := 1.0, 1.0
+					for _, e := range occurrences[abs(literal)] {
+						if e.clause == a {
+							continue
+						}
+						if (cnf[e.clause][e.pos] > 0) == (literal > 0) {
+							same *= 1 - eta[e.clause][e.pos]
+						} else {
+							opposite *= 1 - eta[e.clause][e.pos]
+						}
+					}
+					unsat := (1 - opposite) * same // Forced not to satisfy clause a
+					sat := (1 - same) * opposite
+					free := same * opposite
+					if total := unsat + sat + free; total > 0 {
+						survey *= unsat / total
+					} else {
+						survey = 0
+					}
+				}
+				if math.Abs(survey-eta[a][k]) > spEpsilon {
+					converged = false
+				}
+				eta[a][k] = survey
+			}
+		}
+	}
+	if !converged {
+		return nil
+	}
+
+	biases := make(map[int]float64)
+	trivial := true
+	for variable, edges := range occurrences {
+		positive, negative := 1.0, 1.0 // Products of 1-eta over the clauses where the variable is positive or negative
+		for _, e := range edges {
+			if eta[e.clause][e.pos] > spEpsilon {
+				trivial = false
+			}
+			if cnf[e.clause][e.pos] > 0 {
+				positive *= 1 - eta[e.clause][e.pos]
+			} else {
+				negative *= 1 - eta[e.clause][e.pos]
+			}
+		}
+		forcedTrue := (1 - positive) * negative
+		forcedFalse := (1 - negative) * positive
+		free := positive * negative
+		if total := forcedTrue + forcedFalse + free; total > 0 {
+			biases[variable] = (forcedTrue - forcedFalse) / total
+		}
+	}
+	if trivial {
+		return nil
+	}
+	return biases
+}
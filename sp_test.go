@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// planted3SAT returns m random 3-literal clauses over variables 1..n, each satisfied by a hidden
+// random assignment
+func planted3SAT(n, m int, seed int64) CNF {
+	rng := rand.New(rand.NewSource(seed))
+	planted := make([]bool, n+1)
+	for variable := range planted {
+		planted[variable] = rng.Intn(2) == 1
+	}
+	cnf := CNF{}
+	for len(cnf) < m {
+		clause := Clause{}
+		satisfied := false
+		for _, variable := range rng.Perm(n)[:3] {
+			literal := variable + 1
+			if rng.Intn(2) == 1 {
+				literal = -literal
+			}
+			clause = append(clause, literal)
+			satisfied = satisfied || planted[variable+1] == (literal > 0)
+		}
+		if satisfied {
+			cnf = append(cnf, clause)
+		}
+	}
+	return cnf
+}
+
+func TestSurveyPropagationLargeRandom(t *testing.T) {
+	const n = 2000
+	cnf := planted3SAT(n, 4*n, 7)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := DPLLContext(ctx, cnf, map[int]bool{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("plain DPLL should not finish within a second, got %v", err)
+	}
+	model, ok := SurveyPropagation(cnf, n, 200, 1)
+	if !ok || len(UnsatisfiedClauses(cnf, model)) > 0 {
+		t.Fatalf("got %v with %d falsified clauses, want a model", ok, len(UnsatisfiedClauses(cnf, model)))
+	}
+	if _, ok := SurveyPropagation(CNF{{1}, {-1}}, 1, 10, 1); ok {
+		t.Fatal("a model was reported for (1) AND (-1)")
+	}
+}
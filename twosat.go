@@ -0,0 +1,120 @@
+package main
+
+// Solve2SAT decides a CNF whose clauses have at most two literals in linear time: each clause
+// (a OR b) becomes the implications -a -> b and -b -> a, and the formula is satisfiable exactly
+// when no variable shares a strongly connected component with its negation.
+// The model assigns every variable from 1 to numVars. Constants, repeated literals and
+// tautologies do not count towards the two literals; a formula with a wider clause is decided by
+// DPLL instead, and Solve2SATStrict rejects it.
+func Solve2SAT(cnf CNF, numVars int) (bool, map[int]bool) {
+	satisfiable, model, err := Solve2SATStrict(cnf, numVars)
+	if err == nil {
+		return satisfiable, model
+	}
+	satisfiable, model = solveModel(resolveConstants(cnf))
+	for variable := 1; satisfiable && variable <= numVars; variable++ {
+		if _, exists := model[variable]; !exists {
+			model[variable] = false
+		}
+	}
+	return satisfiable, model
+}
+
+// Solve2SATStrict is Solve2SAT for callers that rely on the linear time: a clause with more than
+// two distinct literals besides constants is reported as a ValidationError rather than solved.
+func Solve2SATStrict(cnf CNF, numVars int) (bool, map[int]bool, error) {
+	for i, clause := range cnf {
+		if containsLiteral(clause, LiteralTrue) || isTautology(clause) {
+			continue
+		}
+		distinct := make(map[int]bool)
+		for _, literal := range clause {
+			if literal != LiteralFalse {
+				distinct[literal] = true
+			}
+		}
+		if len(distinct) > 2 {
+			return false, nil, &ValidationError{ClauseIndex: i, Reason: "clause has more than two literals"}
+		}
+	}
+	cnf = resolveConstants(cnf)
+	if v := maxVariable(cnf, nil); v > numVars {
+		numVars = v
+	}
+	node := func(literal int) int { // Literal x is node 2(x-1), -x is node 2(x-1)+1
+		if literal > 0 {
+			return 2 * (literal - 1)
+		}
+		return 2*(-literal-1) + 1
+	}
+	graph := make([][]int, 2*numVars)
+	cnf, _ = Simplify(cnf, false)
+	for _, clause := range cnf {
+		switch len(clause) {
+		case 0:
+			return false, nil, nil
+		case 1:
+			graph[node(-clause[0])] = append(graph[node(-clause[0])], node(clause[0]))
+		case 2:
+			a, b := clause[0], clause[1]
+			graph[node(-a)] = append(graph[node(-a)], node(b))
+			graph[node(-b)] = append(graph[node(-b)], node(a))
+		}
+	}
+
+	// Tarjan's algorithm numbers the components in reverse topological order
+	index := make([]int, len(graph))
+	lowlink := make([]int, len(graph))
+	component := make([]int, len(graph))
+	onStack := make([]bool, len(graph))
+	for i := range index {
+		index[i] = -1
+	}
+	stack := []int{}
+	counter, components := 0, 0
+	var visit func(v int)
+	visit = func(v int) {
+		index[v], lowlink[v] = counter, counter
+		counter++
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range graph[v] {
+			if index[w] == -1 {
+				visit(w)
+				if lowlink[w] < lowlink[v] {
+					lowlink[v] = lowlink[w]
+				}
+			} else if onStack[w] && index[w] < lowlink[v] {
+				lowlink[v] = index[w]
+			}
+		}
+		if lowlink[v] == index[v] {
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				component[w] = components
+				if w == v {
+					break
+				}
+			}
+			components++
+		}
+	}
+	for v := range graph {
+		if index[v] == -1 {
+			visit(v)
+		}
+	}
+
+	model := make(map[int]bool)
+	for variable := 1; variable <= numVars; variable++ {
+		positive, negative := component[node(variable)], component[node(-variable)]
+		if positive == negative {
+			return false, nil, nil
+		}
+		// A literal whose component comes later in topological order is implied, not implying
+		model[variable] = positive < negative
+	}
+	return true, model, nil
+}
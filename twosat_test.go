@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestSolve2SAT(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	for round := 0; round < 300; round++ {
+		n := 2 + rng.Intn(10)
+		cnf := CNF{}
+		for i := rng.Intn(3 * n); i >= 0; i-- {
+			clause := Clause{}
+			for k := 1 + rng.Intn(2); k > 0; k-- {
+				literal := rng.Intn(n) + 1
+				if rng.Intn(2) == 0 {
+					literal = -literal
+				}
+				clause = append(clause, literal)
+			}
+			cnf = append(cnf, clause)
+		}
+		want := DPLL(cnf, map[int]bool{})
+		got, model := Solve2SAT(cnf, n)
+		if got != want {
+			t.Fatalf("%v: got %v, want %v", cnf, got, want)
+		}
+		if got && len(UnsatisfiedClauses(cnf, model)) > 0 {
+			t.Fatalf("%v: model %v falsifies a clause", cnf, model)
+		}
+		if satisfiable, _ := Solve(cnf); satisfiable != want {
+			t.Fatalf("%v: Solve got %v, want %v", cnf, satisfiable, want)
+		}
+	}
+}
+
+func TestSolve2SATLongClause(t *testing.T) {
+	_, _, err := Solve2SATStrict(CNF{{1, 2}, {1, 2, 3}}, 3)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.ClauseIndex != 1 {
+		t.Fatalf("got %v, want a ValidationError for clause 1", err)
+	}
+	if satisfiable, _, err := Solve2SATStrict(CNF{{1, LiteralFalse, 2}, {-1, 2, 3, LiteralTrue}}, 3); !satisfiable || err != nil {
+		t.Fatal("constants do not count towards the two literals", err)
+	}
+	if satisfiable, _, err := Solve2SATStrict(CNF{{1, 1, 2}, {-1, 2, 1, 3}, {-2}}, 3); !satisfiable || err != nil {
+		t.Fatal("repeated literals and tautologies do not count towards the two literals", err)
+	}
+
+	// By default a wider clause is solved rather than rejected
+	cnf := CNF{{1, 2, 3}, {-1}, {-2}}
+	satisfiable, model := Solve2SAT(cnf, 4)
+	if !satisfiable || len(model) != 4 || len(UnsatisfiedClauses(cnf, model)) != 0 {
+		t.Fatalf("got %v, %v, want a model of %v over 4 variables", satisfiable, model, cnf)
+	}
+	if satisfiable, _ := Solve2SAT(append(cnf, Clause{-3}), 3); satisfiable {
+		t.Fatal("got a model for an unsatisfiable formula with a wide clause")
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+// XORClause constrains the exclusive or of its variables to equal Parity.
+// A variable listed twice cancels out.
+type XORClause struct {
+	Variables []int
+	Parity    bool
+}
+
+// SolveXORSystem solves a system of XOR clauses as linear equations over GF(2) by Gauss-Jordan
+// elimination. It reports false when elimination derives 0 = 1; otherwise the model assigns every
+// variable from 1 to numVars, with the free variables set to false.
+func SolveXORSystem(xors []XORClause, numVars int) (bool, map[int]bool) {
+	for _, x := range xors {
+		for _, variable := range x.Variables {
+			if abs(variable) > numVars {
+				numVars = abs(variable)
+			}
+		}
+	}
+	words := numVars/64 + 1 // Bit i of a row is variable i
+	rows := make([][]uint64, len(xors))
+	for i, x := range xors {
+		rows[i] = make([]uint64, words)
+		for _, variable := range x.Variables {
+			rows[i][abs(variable)/64] ^= 1 << (uint(abs(variable)) % 64)
+		}
+		if x.Parity {
+			rows[i][0] ^= 1 // Bit 0 is unused by variables, so it holds the parity
+		}
+	}
+	bit := func(row []uint64, i int) bool { return row[i/64]>>(uint(i)%64)&1 == 1 }
+
+	pivots := make(map[int]int) // Variable to the row that defines it
+	next := 0                   // Rows above next already have a pivot
+	for variable := 1; variable <= numVars; variable++ {
+		pivot := -1
+		for i := next; i < len(rows); i++ {
+			if bit(rows[i], variable) {
+				pivot = i
+				break
+			}
+		}
+		if pivot == -1 {
+			continue // Free variable
+		}
+		rows[next], rows[pivot] = rows[pivot], rows[next]
+		for i := range rows {
+			if i != next && bit(rows[i], variable) {
+				for w := range rows[i] {
+					rows[i][w] ^= rows[next][w]
+				}
+			}
+		}
+		pivots[variable] = next
+		next++
+	}
+	for _, row := range rows[next:] {
+		if bit(row, 0) { // Every variable was eliminated: 0 = 1
+			return false, nil
+		}
+	}
+
+	model := make(map[int]bool)
+	for variable := 1; variable <= numVars; variable++ {
+		row, pivot := pivots[variable]
+		// Rows are fully reduced, so a pivot row holds only its pivot and free variables
+		model[variable] = pivot && bit(rows[row], 0)
+	}
+	return true, model
+}
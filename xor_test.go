@@ -0,0 +1,62 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// xorHolds reports whether the model satisfies the XOR clause
+func xorHolds(x XORClause, model map[int]bool) bool {
+	parity := false
+	for _, variable := range x.Variables {
+		parity = parity != model[variable]
+	}
+	return parity == x.Parity
+}
+
+func TestSolveXORSystemInconsistent(t *testing.T) {
+	// Adding the three equations gives 0 = 1
+	xors := []XORClause{{[]int{1, 2}, true}, {[]int{2, 3}, true}, {[]int{1, 3}, true}}
+	if satisfiable, model := SolveXORSystem(xors, 3); satisfiable || model != nil {
+		t.Fatalf("got %v, %v, want UNSAT", satisfiable, model)
+	}
+	if satisfiable, _ := SolveXORSystem([]XORClause{{[]int{4, 4}, true}}, 4); satisfiable {
+		t.Fatal("x4 XOR x4 = 1 was solved")
+	}
+}
+
+func TestSolveXORSystem(t *testing.T) {
+	rng := rand.New(rand.NewSource(9))
+	for round := 0; round < 200; round++ {
+		n := 1 + rng.Intn(70) // Crosses the 64-variable word boundary
+		xors := []XORClause{}
+		for i := rng.Intn(n + 3); i >= 0; i-- {
+			variables := []int{}
+			for variable := 1; variable <= n; variable++ {
+				if rng.Intn(3) == 0 {
+					variables = append(variables, variable)
+				}
+			}
+			xors = append(xors, XORClause{variables, rng.Intn(2) == 0})
+		}
+		satisfiable, model := SolveXORSystem(xors, n)
+		if n <= 12 {
+			next := n
+			cnf := CNF{}
+			for _, x := range xors {
+				cnf = append(cnf, xorClauses(x.Variables, x.Parity, &next)...)
+			}
+			if want := DPLL(cnf, map[int]bool{}); satisfiable != want {
+				t.Fatalf("%v: got %v, want %v", xors, satisfiable, want)
+			}
+		}
+		if !satisfiable {
+			continue
+		}
+		for _, x := range xors {
+			if !xorHolds(x, model) {
+				t.Fatalf("model %v falsifies %v", model, x)
+			}
+		}
+	}
+}